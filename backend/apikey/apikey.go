@@ -0,0 +1,146 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// API key dipakai klien mesin (kiosk, partner) sebagai pengganti JWT user.
+// Format key: gfk_<prefix>_<secret>. Prefix disimpan apa adanya untuk lookup,
+// secret hanya disimpan sebagai hash SHA-256.
+
+// Prefix menandai string sebagai API key, bukan JWT
+const Prefix = "gfk_"
+
+// ContextKey adalah key gin context untuk *Key pada request yang memakai API key
+const ContextKey = "api_key"
+
+const prefixBytes = 4 // 8 karakter hex
+
+// ErrInvalid dikembalikan jika key salah, dicabut atau kedaluwarsa
+var ErrInvalid = errors.New("invalid API key")
+
+// Key adalah API key yang sudah diautentikasi
+type Key struct {
+	ID          int
+	Name        string
+	Permissions map[string]bool
+	VenueIDs    map[int]bool
+	RateLimit   int // request per menit
+}
+
+// IsAPIKey mengecek apakah credential berformat API key
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, Prefix)
+}
+
+// Generate membuat API key baru. raw hanya ditampilkan sekali ke admin,
+// yang disimpan hanya prefix dan hash.
+func Generate() (raw, prefix, hash string, err error) {
+	b := make([]byte, prefixBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(b)
+
+	secret, err := auth.RandomToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	return Prefix + prefix + "_" + secret, prefix, auth.HashToken(secret), nil
+}
+
+// parse memisahkan prefix dan secret dari raw key
+func parse(raw string) (prefix, secret string, ok bool) {
+	rest := strings.TrimPrefix(raw, Prefix)
+	if len(rest) < prefixBytes*2+2 || rest[prefixBytes*2] != '_' {
+		return "", "", false
+	}
+	return rest[:prefixBytes*2], rest[prefixBytes*2+1:], true
+}
+
+// Authenticate memvalidasi raw key dan memuat permission serta venue-nya.
+// last_used_at diperbarui paling sering sekali per menit.
+func Authenticate(ctx context.Context, raw, ip string) (*Key, error) {
+	prefix, secret, ok := parse(raw)
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	key := &Key{Permissions: map[string]bool{}, VenueIDs: map[int]bool{}}
+	var hash string
+	err := config.DB.QueryRowContext(ctx, `
+		SELECT id, name, key_hash, rate_limit_per_minute FROM api_keys
+		WHERE prefix = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`, prefix).Scan(&key.ID, &key.Name, &hash, &key.RateLimit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalid
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(auth.HashToken(secret)), []byte(hash)) != 1 {
+		return nil, ErrInvalid
+	}
+
+	rows, err := config.DB.QueryContext(ctx, "SELECT permission FROM api_key_permissions WHERE api_key_id = $1", key.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		key.Permissions[p] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	venues, err := config.DB.QueryContext(ctx, "SELECT venue_id FROM api_key_venues WHERE api_key_id = $1", key.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer venues.Close()
+	for venues.Next() {
+		var id int
+		if err := venues.Scan(&id); err != nil {
+			return nil, err
+		}
+		key.VenueIDs[id] = true
+	}
+	if err := venues.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err = config.DB.ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute' OR last_used_ip IS DISTINCT FROM $2)
+	`, key.ID, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// FromContext mengembalikan API key request, nil jika request memakai JWT user
+func FromContext(c *gin.Context) *Key {
+	v, ok := c.Get(ContextKey)
+	if !ok {
+		return nil
+	}
+	key, _ := v.(*Key)
+	return key
+}
@@ -0,0 +1,199 @@
+package apperror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Kode error yang bisa dibaca mesin, dipakai client untuk membedakan jenis error
+const (
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeBadRequest       = "BAD_REQUEST"
+	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeInvalidToken     = "INVALID_TOKEN"
+	CodeForbidden        = "FORBIDDEN"
+	CodeNotFound         = "NOT_FOUND"
+	CodeConflict         = "CONFLICT"
+	CodeBookingConflict  = "BOOKING_CONFLICT"
+	CodeEmailNotVerified = "EMAIL_NOT_VERIFIED"
+	CodeMFARequired      = "MFA_REQUIRED"
+	CodeTooManyRequests  = "TOO_MANY_REQUESTS"
+	CodeIdempotencyKey   = "IDEMPOTENCY_KEY_REUSED"
+	CodeTimeout          = "TIMEOUT"
+	CodeInternal         = "INTERNAL_ERROR"
+)
+
+// FieldError menjelaskan kesalahan validasi pada satu field request
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Message string `json:"message" example:"must be a valid email address"`
+}
+
+// Error adalah satu-satunya tipe error yang dikembalikan handler ke client.
+// Cause tidak pernah dikirim ke client, hanya untuk log di server.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details []FieldError
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// WithCause menyimpan error asli untuk keperluan log di server
+func (e *Error) WithCause(cause error) *Error {
+	e.Cause = cause
+	return e
+}
+
+// New membuat error dengan status, kode dan pesan tertentu
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest untuk request yang tidak bisa diproses (misalnya JSON rusak)
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// Unauthorized untuk request tanpa kredensial yang valid
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden untuk user yang tidak punya akses ke resource
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+// NotFound untuk resource yang tidak ditemukan
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+// Conflict untuk data yang bentrok dengan data yang sudah ada
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// BookingConflict untuk jadwal booking yang bentrok dengan booking lain
+func BookingConflict(message string) *Error {
+	return New(http.StatusConflict, CodeBookingConflict, message)
+}
+
+// TooManyRequests untuk request yang melewati batas (throttling)
+func TooManyRequests(message string) *Error {
+	return New(http.StatusTooManyRequests, CodeTooManyRequests, message)
+}
+
+// IdempotencyKeyReused untuk Idempotency-Key yang dipakai ulang dengan request berbeda
+func IdempotencyKeyReused() *Error {
+	return New(http.StatusUnprocessableEntity, CodeIdempotencyKey,
+		"Idempotency-Key was already used for a different request")
+}
+
+// Validation membuat error validasi dengan detail per field
+func Validation(details ...FieldError) *Error {
+	return &Error{
+		Status:  http.StatusBadRequest,
+		Code:    CodeValidationFailed,
+		Message: "Request validation failed",
+		Details: details,
+	}
+}
+
+// Timeout untuk request yang melewati batas waktu (misalnya query database terlalu lama)
+func Timeout(cause error) *Error {
+	return New(http.StatusServiceUnavailable, CodeTimeout, "Request timed out, please try again").WithCause(cause)
+}
+
+// Internal membungkus error internal. Pesan ke client selalu generik.
+// Error karena deadline request habis dijadikan Timeout.
+func Internal(cause error) *Error {
+	if errors.Is(cause, context.DeadlineExceeded) {
+		return Timeout(cause)
+	}
+	return &Error{
+		Status:  http.StatusInternalServerError,
+		Code:    CodeInternal,
+		Message: "Internal server error",
+		Cause:   cause,
+	}
+}
+
+// FromDB menerjemahkan error dari database menjadi Error yang aman dikirim ke client.
+// notFound dipakai sebagai pesan jika query tidak menemukan baris.
+func FromDB(err error, notFound string) *Error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return NotFound(notFound)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return Conflict("Resource already exists").WithCause(err)
+		case "23503": // foreign_key_violation
+			return Conflict("Resource is referenced by or references missing data").WithCause(err)
+		case "23P01": // exclusion_violation
+			return BookingConflict("Court is already booked for the requested time").WithCause(err)
+		case "22P02", "22007", "22008": // invalid_text_representation, invalid datetime
+			e := Validation()
+			e.Message = "Request contains an invalid value"
+			return e.WithCause(err)
+		}
+	}
+
+	return Internal(err)
+}
+
+// As mengubah error apapun menjadi *Error. Error yang tidak dikenal dianggap internal.
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// Response adalah format JSON untuk semua response error
+type Response struct {
+	Success bool `json:"success" example:"false"`
+	Error   Body `json:"error"`
+}
+
+// Body berisi detail error di dalam Response
+type Body struct {
+	Code      string       `json:"code" example:"VALIDATION_FAILED"`
+	Message   string       `json:"message" example:"Request validation failed"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty" example:"3f2b8c1e9a7d4e60b1c2d3e4f5a6b7c8"`
+}
+
+// Response membuat body JSON dari error untuk request tertentu
+func (e *Error) Response(requestID string) Response {
+	return Response{
+		Success: false,
+		Error: Body{
+			Code:      e.Code,
+			Message:   e.Message,
+			Details:   e.Details,
+			RequestID: requestID,
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FromBinding menerjemahkan error dari c.ShouldBindJSON / ShouldBindQuery
+// menjadi error validasi dengan detail per field.
+func FromBinding(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			details = append(details, FieldError{
+				Field:   fe.Field(),
+				Message: fieldMessage(fe),
+			})
+		}
+		return Validation(details...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return Validation(FieldError{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type.String()),
+		})
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return BadRequest("Request body is not valid JSON")
+	}
+
+	if errors.Is(err, io.EOF) {
+		return BadRequest("Request body is required")
+	}
+
+	return BadRequest("Invalid request").WithCause(err)
+}
+
+// fieldMessage membuat pesan yang mudah dibaca untuk satu tag validasi
+func fieldMessage(fe validator.FieldError) string {
+	if msg, ok := customMessages[fe.Tag()]; ok {
+		return msg(fe)
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters", fe.Param())
+	case "numeric":
+		return "must contain only digits"
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", fe.Param())
+	case "nefield":
+		return fmt.Sprintf("must differ from %s", fe.Param())
+	case "timezone":
+		return "must be a valid IANA timezone, e.g. Asia/Jakarta"
+	case "latitude", "longitude":
+		return fmt.Sprintf("must be a valid %s", fe.Tag())
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+	}
+}
+
+var customMessages = map[string]func(validator.FieldError) string{}
+
+// RegisterMessage mendaftarkan pesan untuk tag validasi custom
+func RegisterMessage(tag string, msg func(validator.FieldError) string) {
+	customMessages[tag] = msg
+}
@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Action yang dicatat di audit_log
+const (
+	ActionCreate         = "create"
+	ActionUpdate         = "update"
+	ActionDelete         = "delete"
+	ActionRestore        = "restore"
+	ActionPasswordChange = "password_change"
+	ActionPasswordReset  = "password_reset"
+	ActionCheckIn        = "checkin"
+	ActionForceLogout    = "force_logout"
+	ActionUnlock         = "unlock"
+	ActionRevoke         = "revoke"
+)
+
+// Jenis entity yang dicatat di audit_log
+const (
+	EntityCourt   = "court"
+	EntityBooking = "booking"
+	EntityUser    = "user"
+	EntityPayment = "payment"
+	EntityRole    = "role"
+	EntityAPIKey  = "api_key"
+)
+
+// redacted adalah kolom rahasia yang tidak pernah disimpan di audit log
+var redacted = []string{"password", "totp_secret", "totp_last_step", "key_hash", "token_hash"}
+
+// Record mencatat satu perubahan data beserta pelaku, IP dan request ID.
+// before dan after adalah JSON baris data (misalnya dari to_jsonb di RETURNING),
+// nil untuk create (before) dan delete (after). Untuk update hanya field yang
+// berubah yang disimpan. Perubahan sudah terjadi saat Record dipanggil, jadi
+// kegagalan mencatat hanya di-log dan tidak menggagalkan request.
+func Record(c *gin.Context, action, entityType, entityID string, before, after []byte) {
+	// Perubahan sudah terjadi, jadi pencatatan tidak ikut dibatalkan saat request timeout
+	ctx := context.WithoutCancel(c.Request.Context())
+	b, a, err := diff(before, after)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "audit record failed", "action", action, "entity_type", entityType, "entity_id", entityID, "error", err)
+		return
+	}
+
+	var actorUserID, actorAPIKeyID *int
+	if id := c.GetInt("user_id"); id > 0 {
+		actorUserID = &id
+	}
+	if key := apikey.FromContext(c); key != nil {
+		actorAPIKeyID = &key.ID
+	}
+
+	_, err = config.DB.ExecContext(ctx, `
+		INSERT INTO audit_log (actor_user_id, actor_api_key_id, actor, action, entity_type, entity_id, before, after, ip_address, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, actorUserID, actorAPIKeyID, c.GetString("username"), action, entityType, entityID, b, a, c.ClientIP(), c.GetString("request_id"))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "audit record failed", "action", action, "entity_type", entityType, "entity_id", entityID, "error", err)
+	}
+}
+
+// diff membuang kolom rahasia dan, jika before dan after sama-sama ada,
+// hanya menyisakan field yang nilainya berubah. Hasil nil disimpan sebagai NULL.
+func diff(before, after []byte) (*string, *string, error) {
+	b, err := decode(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := decode(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if b != nil && a != nil {
+		for k, v := range b {
+			if reflect.DeepEqual(v, a[k]) {
+				delete(b, k)
+				delete(a, k)
+			}
+		}
+	}
+
+	bs, err := encode(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	as, err := encode(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bs, as, nil
+}
+
+func decode(data []byte) (map[string]any, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for _, k := range redacted {
+		delete(m, k)
+	}
+	return m, nil
+}
+
+func encode(m map[string]any) (*string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	return &s, nil
+}
@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name                  string
+		before, after         string // "" = nil
+		wantBefore, wantAfter string // "" = NULL
+	}{
+		{
+			name:      "create keeps all fields",
+			after:     `{"id":1,"name":"Lapangan A","price":100000}`,
+			wantAfter: `{"id":1,"name":"Lapangan A","price":100000}`,
+		},
+		{
+			name:       "delete keeps all fields",
+			before:     `{"id":1,"name":"Lapangan A"}`,
+			wantBefore: `{"id":1,"name":"Lapangan A"}`,
+		},
+		{
+			name:       "update keeps only changed fields",
+			before:     `{"id":1,"name":"Lapangan A","price":100000,"tags":["indoor"]}`,
+			after:      `{"id":1,"name":"Lapangan A","price":120000,"tags":["indoor"]}`,
+			wantBefore: `{"price":100000}`,
+			wantAfter:  `{"price":120000}`,
+		},
+		{
+			name:       "update with added field",
+			before:     `{"id":1,"deleted_at":null}`,
+			after:      `{"id":1,"deleted_at":"2026-01-01T00:00:00Z","restored":true}`,
+			wantBefore: `{"deleted_at":null}`,
+			wantAfter:  `{"deleted_at":"2026-01-01T00:00:00Z","restored":true}`,
+		},
+		{
+			name:       "no change",
+			before:     `{"id":1,"role":"client"}`,
+			after:      `{"id":1,"role":"client"}`,
+			wantBefore: `{}`,
+			wantAfter:  `{}`,
+		},
+		{
+			name:       "secrets are redacted",
+			before:     `{"id":1,"email":"a@example.com","password":"$2a$10$old","totp_secret":"JBSWY3DP","totp_last_step":1}`,
+			after:      `{"id":1,"email":"b@example.com","password":"$2a$10$new","totp_secret":"KRSXG5CT","totp_last_step":2}`,
+			wantBefore: `{"email":"a@example.com"}`,
+			wantAfter:  `{"email":"b@example.com"}`,
+		},
+		{
+			name:      "key and token hashes are redacted on create",
+			after:     `{"id":3,"name":"kiosk","key_hash":"abc","token_hash":"def"}`,
+			wantAfter: `{"id":3,"name":"kiosk"}`,
+		},
+		{
+			name: "password change only",
+			// Hanya kolom rahasia yang berubah, jadi tidak ada isi yang tersimpan
+			before:     `{"id":1,"password":"old"}`,
+			after:      `{"id":1,"password":"new"}`,
+			wantBefore: `{}`,
+			wantAfter:  `{}`,
+		},
+		{name: "no data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, a, err := diff(bytesOrNil(tt.before), bytesOrNil(tt.after))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSON(t, "before", b, tt.wantBefore)
+			assertJSON(t, "after", a, tt.wantAfter)
+		})
+	}
+}
+
+func TestDiffInvalidJSON(t *testing.T) {
+	if _, _, err := diff([]byte(`{"id":`), nil); err == nil {
+		t.Error("diff() with invalid JSON = nil error, want error")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = prev
+		db.Close()
+	})
+
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name      string
+		setup     func(c *gin.Context)
+		actorUser any // nil = NULL
+		actorKey  any
+		actor     string
+	}{
+		{
+			name: "user",
+			setup: func(c *gin.Context) {
+				c.Set("user_id", 5)
+				c.Set("username", "budi")
+			},
+			actorUser: 5,
+			actor:     "budi",
+		},
+		{
+			name: "api key",
+			setup: func(c *gin.Context) {
+				c.Set(apikey.ContextKey, &apikey.Key{ID: 9})
+				c.Set("username", "apikey:kiosk")
+			},
+			actorKey: 9,
+			actor:    "apikey:kiosk",
+		},
+		{
+			// Misalnya reset password lewat link email: tidak ada user yang login
+			name:  "anonymous",
+			setup: func(*gin.Context) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/users/7", nil)
+			c.Request.RemoteAddr = "203.0.113.7:51000"
+			c.Set("request_id", "req-1")
+			tt.setup(c)
+
+			mock.ExpectExec(`INSERT INTO audit_log`).
+				WithArgs(tt.actorUser, tt.actorKey, tt.actor, ActionUpdate, EntityUser, "7",
+					`{"role":"client"}`, `{"role":"admin"}`, "203.0.113.7", "req-1").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			Record(c, ActionUpdate, EntityUser, "7",
+				[]byte(`{"id":7,"role":"client","password":"x"}`),
+				[]byte(`{"id":7,"role":"admin","password":"x"}`))
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func bytesOrNil(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return []byte(s)
+}
+
+func assertJSON(t *testing.T, name string, got *string, want string) {
+	t.Helper()
+	if want == "" {
+		if got != nil {
+			t.Errorf("%s = %s, want NULL", name, *got)
+		}
+		return
+	}
+	if got == nil {
+		t.Errorf("%s = NULL, want %s", name, want)
+		return
+	}
+	var g, w any
+	if err := json.Unmarshal([]byte(*got), &g); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("%s = %s, want %s", name, *got, want)
+	}
+}
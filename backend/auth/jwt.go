@@ -2,72 +2,80 @@ package auth
 
 import (
 	"errors"
-	"os"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// Semua token diterbitkan oleh API ini dan ditandatangani dengan kunci yang dipublikasikan lewat JWKS.
+// Jenis token dibedakan lewat header typ dan claim aud, dan setiap parser mengecek keduanya.
+// Service lain cukup menerima aud "gofutsal-api" (typ at+jwt) supaya refresh token,
+// challenge 2FA dan token verifikasi email tidak bisa dipakai sebagai access token.
+const issuer = "gofutsal-api"
+
+// tokenKind adalah jenis token: typ di header (RFC 8725) dan aud di claims
+type tokenKind struct {
+	typ      string
+	audience string
+}
+
+var (
+	accessKind  = tokenKind{typ: "at+jwt", audience: "gofutsal-api"}
+	refreshKind = tokenKind{typ: "refresh+jwt", audience: "gofutsal-api-refresh"}
+	mfaKind     = tokenKind{typ: "mfa+jwt", audience: "gofutsal-api-mfa"}
+	verifyKind  = tokenKind{typ: "verify+jwt", audience: "gofutsal-api-verify"}
+)
+
 // JWTClaim represents the JWT claims structure
 type JWTClaim struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // session login, kosong untuk token selain hasil login
 	jwt.RegisteredClaims
 }
 
 // GenerateJWT generates a new JWT token for authenticated user
 // @Summary Generate JWT Token
 // @Description Generate JWT token dengan user information
-func GenerateJWT(userID int, username, email, role string) (string, error) {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "default-secret-change-in-production"
-	}
-
+func GenerateJWT(userID int, username, email, role, sessionID string) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
 
 	claims := &JWTClaim{
-		UserID:   userID,
-		Username: username,
-		Email:    email,
-		Role:     role,
+		UserID:    userID,
+		Username:  username,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "gofutsal-api",
 			Subject:   strconv.Itoa(userID),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(jwtSecret))
-
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
+	return signToken(accessKind, claims)
 }
 
 // ValidateToken validates and parses JWT token
 // @Summary Validate JWT Token
 // @Description Validate JWT token dan return claims
 func ValidateToken(signedToken string) (*JWTClaim, error) {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "default-secret-change-in-production"
-	}
+	// Refresh token dan token lain tidak boleh dipakai sebagai access token
+	return parseToken(accessKind, signedToken)
+}
 
+// parseToken memverifikasi signature, masa berlaku dan jenis token (typ, iss dan aud)
+func parseToken(kind tokenKind, signedToken string) (*JWTClaim, error) {
 	token, err := jwt.ParseWithClaims(
 		signedToken,
 		&JWTClaim{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecret), nil
-		},
+		verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
 	)
 
 	if err != nil {
@@ -83,30 +91,12 @@ func ValidateToken(signedToken string) (*JWTClaim, error) {
 		return nil, errors.New("token expired")
 	}
 
-	return claims, nil
-}
-
-// GenerateRefreshToken generates refresh token dengan longer expiration
-// @Summary Generate Refresh Token
-// @Description Generate refresh token untuk renew access token
-func GenerateRefreshToken(userID int) (string, error) {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "default-secret-change-in-production"
+	if typ, _ := token.Header["typ"].(string); typ != kind.typ {
+		return nil, fmt.Errorf("unexpected token type %q", typ)
 	}
-
-	expirationTime := time.Now().Add(7 * 24 * time.Hour) // 7 days
-
-	claims := &JWTClaim{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "gofutsal-api-refresh",
-			Subject:   strconv.Itoa(userID),
-		},
+	if claims.Issuer != issuer || !claims.VerifyAudience(kind.audience, true) {
+		return nil, fmt.Errorf("token is not a %s token", kind.typ)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecret))
+	return claims, nil
 }
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestTokenKindsAreNotInterchangeable(t *testing.T) {
+	sign := func(kind tokenKind) string {
+		t.Helper()
+		token, err := signToken(kind, &JWTClaim{
+			UserID:    7,
+			SessionID: "sid-test",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        "jti-test",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	kinds := map[string]tokenKind{
+		"access":  accessKind,
+		"refresh": refreshKind,
+		"mfa":     mfaKind,
+		"verify":  verifyKind,
+	}
+	parsers := map[string]func(string) (*JWTClaim, error){
+		"access": ValidateToken,
+		"mfa":    ValidateMFAToken,
+		"verify": ValidateVerificationToken,
+		// ValidateRefreshToken juga mengecek database, jadi cukup parser-nya
+		"refresh": func(s string) (*JWTClaim, error) { return parseToken(refreshKind, s) },
+	}
+
+	for tokenName, kind := range kinds {
+		token := sign(kind)
+		for parserName, parse := range parsers {
+			_, err := parse(token)
+			if want := tokenName == parserName; (err == nil) != want {
+				t.Errorf("%s token accepted by %s parser = %v, want %v (err %v)", tokenName, parserName, err == nil, want, err)
+			}
+		}
+	}
+}
+
+func TestAccessTokenClaimsForOtherServices(t *testing.T) {
+	signed, err := GenerateJWT(7, "budi", "budi@example.com", "client", "sid-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Service lain hanya melihat header dan claims standar
+	token, _, err := jwt.NewParser().ParseUnverified(signed, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ := token.Header["typ"]; typ != "at+jwt" {
+		t.Errorf("typ = %v, want at+jwt", typ)
+	}
+	if kid, _ := token.Header["kid"].(string); kid == "" {
+		t.Error("kid header is missing")
+	}
+	claims := token.Claims.(*jwt.RegisteredClaims)
+	if claims.Issuer != "gofutsal-api" || !claims.VerifyAudience("gofutsal-api", true) {
+		t.Errorf("iss = %q, aud = %v, want gofutsal-api", claims.Issuer, claims.Audience)
+	}
+}
+
+func TestParseTokenRejectsMissingType(t *testing.T) {
+	// Token dengan aud yang benar tetapi tanpa typ (misalnya dibuat sebelum typ ada) ditolak
+	ks, err := currentKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(ks.active.method, &JWTClaim{
+		UserID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{accessKind.audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	token.Header["kid"] = ks.active.kid
+	delete(token.Header, "typ")
+	signed, err := token.SignedString(ks.active.private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ValidateToken(signed)
+	if err == nil || !strings.Contains(err.Error(), "token type") {
+		t.Errorf("ValidateToken() = %v, want token type error", err)
+	}
+}
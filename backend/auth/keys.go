@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Token ditandatangani dengan kunci asimetris (RS256 atau EdDSA) supaya service lain
+// bisa memverifikasi token lewat JWKS tanpa bisa membuat token.
+//
+// Kunci dibaca dari JWT_KEYS_DIR, satu file PEM per kunci dengan nama file sebagai kid
+// (misalnya 2026-10.pem). File private key dipakai untuk sign dan verify, file public key
+// hanya untuk verify (kunci lama yang tokennya masih berlaku). Kunci untuk sign dipilih
+// dengan JWT_ACTIVE_KID, default private key dengan kid terakhir secara urutan nama.
+//
+// Jika JWT_KEYS_DIR tidak diisi, kunci Ed25519 sementara dibuat saat start (hanya untuk
+// development, semua token tidak berlaku lagi setelah restart).
+
+// minRSABits adalah ukuran minimal kunci RSA yang diterima
+const minRSABits = 2048
+
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer // nil untuk kunci yang hanya dipakai verify
+	public  crypto.PublicKey
+}
+
+type keySet struct {
+	active *signingKey
+	byKID  map[string]*signingKey
+	kids   []string // urutan kid untuk JWKS
+}
+
+var (
+	keysMu sync.RWMutex
+	keys   *keySet
+)
+
+// LoadKeys membaca kunci dari dir (JWT_KEYS_DIR) dengan kunci aktif activeKID.
+// Dipanggil saat start supaya konfigurasi kunci yang salah langsung ketahuan.
+func LoadKeys(dir, activeKID string) error {
+	ks, err := loadKeySet(dir, activeKID)
+	if err != nil {
+		return err
+	}
+
+	keysMu.Lock()
+	keys = ks
+	keysMu.Unlock()
+	slog.Info("JWT signing key loaded", "kid", ks.active.kid, "alg", ks.active.method.Alg(), "verification_keys", len(ks.kids))
+	return nil
+}
+
+// currentKeys mengembalikan key set, membuat kunci sementara jika LoadKeys belum dipanggil
+func currentKeys() (*keySet, error) {
+	keysMu.RLock()
+	ks := keys
+	keysMu.RUnlock()
+	if ks != nil {
+		return ks, nil
+	}
+
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if keys == nil {
+		ks, err := loadKeySet("", "")
+		if err != nil {
+			return nil, err
+		}
+		keys = ks
+	}
+	return keys, nil
+}
+
+func loadKeySet(dir, activeKID string) (*keySet, error) {
+	ks := &keySet{byKID: map[string]*signingKey{}}
+
+	if dir == "" {
+		slog.Warn("JWT_KEYS_DIR is not set, using an ephemeral Ed25519 key (tokens are invalidated on restart)")
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		kid, err := RandomToken(8)
+		if err != nil {
+			return nil, err
+		}
+		key := &signingKey{kid: "dev-" + kid, method: jwt.SigningMethodEdDSA, private: private, public: public}
+		ks.byKID[key.kid] = key
+		ks.kids = []string{key.kid}
+		ks.active = key
+		return ks, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		key, err := readKey(file, kid)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", file, err)
+		}
+		ks.byKID[kid] = key
+		ks.kids = append(ks.kids, kid)
+		if key.private != nil && activeKID == "" {
+			ks.active = key
+		}
+	}
+
+	if activeKID != "" {
+		ks.active = ks.byKID[activeKID]
+		if ks.active == nil {
+			return nil, fmt.Errorf("JWT_ACTIVE_KID %q not found in %s", activeKID, dir)
+		}
+	}
+	if ks.active == nil || ks.active.private == nil {
+		return nil, fmt.Errorf("no private signing key found in %s", dir)
+	}
+	return ks, nil
+}
+
+// readKey membaca satu file PEM berisi private key (PKCS#1/PKCS#8) atau public key (PKIX)
+func readKey(file, kid string) (*signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{kid: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+
+	if rsaKey, ok := key.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+	}
+	return key, nil
+}
+
+// signToken menandatangani claims dengan kunci aktif, mengisi iss dan aud sesuai jenis token,
+// dan menaruh kid serta typ di header
+func signToken(kind tokenKind, claims *JWTClaim) (string, error) {
+	ks, err := currentKeys()
+	if err != nil {
+		return "", err
+	}
+
+	claims.Issuer = issuer
+	claims.Audience = jwt.ClaimStrings{kind.audience}
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.kid
+	token.Header["typ"] = kind.typ
+	return token.SignedString(ks.active.private)
+}
+
+// verificationKey mencari public key berdasarkan kid di header token
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	ks, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.byKID[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for kid %q", token.Method.Alg(), kid)
+	}
+	return key.public, nil
+}
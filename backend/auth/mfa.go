@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// MFATokenTTL adalah waktu yang diberikan untuk memasukkan kode 2FA setelah password benar
+const MFATokenTTL = 5 * time.Minute
+
+// GenerateMFAToken membuat challenge token untuk langkah kedua login.
+// Token ini tidak bisa dipakai sebagai access token.
+func GenerateMFAToken(userID int) (string, error) {
+	claims := &JWTClaim{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(MFATokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   strconv.Itoa(userID),
+		},
+	}
+
+	return signToken(mfaKind, claims)
+}
+
+// ValidateMFAToken memvalidasi challenge token dari langkah pertama login
+func ValidateMFAToken(signedToken string) (*JWTClaim, error) {
+	return parseToken(mfaKind, signedToken)
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// RefreshTokenTTL adalah masa berlaku refresh token
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// GenerateRefreshToken generates refresh token dengan longer expiration.
+// ID token (jti) disimpan di tabel refresh_tokens supaya bisa dicabut,
+// bersama session login yang memilikinya.
+// @Summary Generate Refresh Token
+// @Description Generate refresh token untuk renew access token
+func GenerateRefreshToken(ctx context.Context, userID int, sessionID string) (string, error) {
+	tokenID, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	expirationTime := time.Now().Add(RefreshTokenTTL)
+
+	claims := &JWTClaim{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   strconv.Itoa(userID),
+		},
+	}
+
+	signed, err := signToken(refreshKind, claims)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = config.DB.ExecContext(ctx,
+		"INSERT INTO refresh_tokens (id, user_id, session_id, expires_at) VALUES ($1, $2, $3, $4)",
+		tokenID, userID, sessionID, expirationTime,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return signed, nil
+}
+
+// ValidateRefreshToken memvalidasi refresh token dan memastikan token maupun sessionnya belum dicabut.
+// Refresh token tanpa sid ditolak supaya access token baru selalu terikat ke session.
+func ValidateRefreshToken(ctx context.Context, signedToken string) (*JWTClaim, error) {
+	claims, err := parseToken(refreshKind, signedToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.ID == "" || claims.SessionID == "" {
+		return nil, errors.New("not a refresh token")
+	}
+
+	var active bool
+	err = config.DB.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM refresh_tokens r JOIN sessions s ON s.id = r.session_id
+			WHERE r.id = $1 AND r.user_id = $2 AND r.session_id = $3
+			  AND r.revoked_at IS NULL AND r.expires_at > NOW()
+			  AND s.revoked_at IS NULL
+		)
+	`, claims.ID, claims.UserID, claims.SessionID).Scan(&active)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, errors.New("refresh token revoked")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HenryKristofani/GoFutsal/config"
+)
+
+// CreateSession mencatat session baru saat login. Session berlaku selama refresh token-nya.
+func CreateSession(ctx context.Context, userID int, userAgent, ip string) (string, error) {
+	id, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = config.DB.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + $5 * INTERVAL '1 second')
+	`, id, userID, userAgent, ip, int(RefreshTokenTTL.Seconds()))
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// TouchSession mengecek session masih aktif dan memperbarui last_seen_at
+// (paling sering sekali per menit supaya tidak menulis di setiap request)
+func TouchSession(ctx context.Context, sessionID string, userID int, ip string) (bool, error) {
+	var active bool
+	err := config.DB.QueryRowContext(ctx, `
+		WITH s AS (
+			SELECT id FROM sessions
+			WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+		), touched AS (
+			UPDATE sessions SET last_seen_at = NOW(), ip_address = $3
+			WHERE id IN (SELECT id FROM s)
+			  AND (last_seen_at < NOW() - INTERVAL '1 minute' OR ip_address <> $3)
+		)
+		SELECT EXISTS (SELECT 1 FROM s)
+	`, sessionID, userID, ip).Scan(&active)
+	return active, err
+}
+
+// RevokeSession mencabut satu session milik user beserta refresh token-nya.
+// Mengembalikan false jika session tidak ditemukan atau sudah dicabut.
+func RevokeSession(ctx context.Context, sessionID string, userID int) (bool, error) {
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL",
+		sessionID, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE session_id = $1 AND revoked_at IS NULL", sessionID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// RevokeSessions mencabut semua session dan refresh token milik user di dalam transaksi,
+// kecuali session except (kosong berarti semua). Dipanggil saat password diganti
+// atau di-reset dan saat admin memaksa logout.
+func RevokeSessions(ctx context.Context, tx *sql.Tx, userID int, except string) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL",
+		userID, except,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND session_id IS DISTINCT FROM $2 AND revoked_at IS NULL
+	`, userID, except)
+	return err
+}
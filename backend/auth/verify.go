@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// VerificationTokenTTL adalah masa berlaku link verifikasi email
+const VerificationTokenTTL = 24 * time.Hour
+
+// GenerateVerificationToken membuat token bertanda tangan untuk link verifikasi email.
+// Email ikut disimpan supaya link lama tidak berlaku lagi jika email diganti.
+func GenerateVerificationToken(userID int, email string) (string, error) {
+	claims := &JWTClaim{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(VerificationTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   strconv.Itoa(userID),
+		},
+	}
+
+	return signToken(verifyKind, claims)
+}
+
+// ValidateVerificationToken memvalidasi token dari link verifikasi email
+func ValidateVerificationToken(signedToken string) (*JWTClaim, error) {
+	return parseToken(verifyKind, signedToken)
+}
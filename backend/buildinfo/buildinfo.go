@@ -0,0 +1,28 @@
+package buildinfo
+
+import "runtime/debug"
+
+// Version dan Commit diisi saat build lewat ldflags, misalnya:
+//
+//	go build -ldflags "-X github.com/HenryKristofani/GoFutsal/buildinfo.Version=1.2.0 -X github.com/HenryKristofani/GoFutsal/buildinfo.Commit=$(git rev-parse --short HEAD)"
+//
+// Jika Commit tidak diisi, dipakai revisi VCS yang disematkan go build (jika ada).
+var (
+	Version = "dev"
+	Commit  = "unknown"
+)
+
+func init() {
+	if Commit != "unknown" {
+		return
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && len(s.Value) >= 7 {
+			Commit = s.Value[:7]
+		}
+	}
+}
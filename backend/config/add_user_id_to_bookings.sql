@@ -1,5 +1,5 @@
 -- Add user_id column to bookings table for relationship with users
-ALTER TABLE bookings ADD COLUMN user_id INTEGER REFERENCES users(id);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id);
 
 -- Optional: Create index for better query performance
-CREATE INDEX idx_bookings_user_id ON bookings(user_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_bookings_user_id ON bookings(user_id);
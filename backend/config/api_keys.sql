@@ -0,0 +1,40 @@
+-- API key untuk klien mesin (kiosk, partner). Hanya hash yang disimpan,
+-- prefix dipakai untuk mencari key dan ditampilkan di daftar key.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL UNIQUE,
+    key_hash CHAR(64) NOT NULL,
+    rate_limit_per_minute INTEGER NOT NULL DEFAULT 60 CHECK (rate_limit_per_minute > 0),
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS api_key_permissions (
+    api_key_id INTEGER NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (api_key_id, permission)
+);
+
+-- Venue yang boleh diakses key. Key dengan permission venues:all tidak dibatasi venue.
+CREATE TABLE IF NOT EXISTS api_key_venues (
+    api_key_id INTEGER NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    venue_id INTEGER NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    PRIMARY KEY (api_key_id, venue_id)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('apikeys:manage', 'Membuat, melihat dan mencabut API key')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'apikeys:manage')
+ON CONFLICT DO NOTHING;
+
+-- Check-in dan pembayaran lewat API key tidak punya user
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_in_by_api_key INTEGER REFERENCES api_keys(id) ON DELETE SET NULL;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS received_by_api_key INTEGER REFERENCES api_keys(id) ON DELETE SET NULL;
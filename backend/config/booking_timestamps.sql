@@ -0,0 +1,38 @@
+-- Simpan waktu booking sebagai timestamptz dan timezone venue per lapangan.
+-- Kolom booking_date, start_time dan end_time tetap ada untuk kompatibilitas,
+-- tetapi start_at dan end_at yang menjadi acuan.
+ALTER TABLE courts ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS start_at TIMESTAMPTZ;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS end_at TIMESTAMPTZ;
+
+-- Isi start_at/end_at untuk booking lama dari jam lokal lapangan.
+-- Jam selesai yang tidak lebih besar dari jam mulai berarti lewat tengah malam.
+UPDATE bookings b
+SET start_at = (b.booking_date::text || ' ' || b.start_time::text)::timestamp AT TIME ZONE c.timezone,
+    end_at = ((b.booking_date::text || ' ' || b.end_time::text)::timestamp
+              + CASE WHEN b.end_time::time <= b.start_time::time THEN INTERVAL '1 day' ELSE INTERVAL '0' END)
+             AT TIME ZONE c.timezone
+FROM courts c
+WHERE c.id = b.court_id AND b.start_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_court_start_end ON bookings(court_id, start_at, end_at);
+
+-- Cegah booking bentrok di level database (untuk request yang masuk bersamaan)
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'bookings_no_overlap'
+    ) THEN
+        BEGIN
+            ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+                EXCLUDE USING gist (court_id WITH =, tstzrange(start_at, end_at) WITH &&);
+            RAISE NOTICE 'Exclusion constraint added to bookings';
+        EXCEPTION WHEN exclusion_violation THEN
+            RAISE WARNING 'Existing bookings overlap, bookings_no_overlap not added';
+        END;
+    END IF;
+END $$;
@@ -0,0 +1,31 @@
+-- Username dan email unik tanpa membedakan huruf besar/kecil, supaya login
+-- dengan username atau email tidak bisa cocok ke lebih dari satu akun.
+-- Jika sudah ada duplikat (misalnya "John" dan "john"), index tidak dibuat dan
+-- migration gagal dengan daftar duplikat di log sampai datanya dibereskan.
+DO $$
+DECLARE
+    dup_usernames TEXT;
+    dup_emails TEXT;
+BEGIN
+    SELECT string_agg(format('%s (ids %s)', name, ids), ', ')
+    INTO dup_usernames
+    FROM (
+        SELECT LOWER(username) AS name, string_agg(id::TEXT, ',' ORDER BY id) AS ids
+        FROM users GROUP BY LOWER(username) HAVING COUNT(*) > 1
+    ) d;
+
+    SELECT string_agg(format('%s (ids %s)', name, ids), ', ')
+    INTO dup_emails
+    FROM (
+        SELECT LOWER(email) AS name, string_agg(id::TEXT, ',' ORDER BY id) AS ids
+        FROM users GROUP BY LOWER(email) HAVING COUNT(*) > 1
+    ) d;
+
+    IF dup_usernames IS NOT NULL OR dup_emails IS NOT NULL THEN
+        RAISE EXCEPTION 'case-insensitive duplicate identifiers found, resolve them to enable unique indexes. usernames: %; emails: %',
+            COALESCE(dup_usernames, 'none'), COALESCE(dup_emails, 'none');
+    END IF;
+
+    CREATE UNIQUE INDEX IF NOT EXISTS users_username_lower_key ON users (LOWER(username));
+    CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (LOWER(email));
+END $$;
@@ -0,0 +1,350 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config adalah seluruh konfigurasi aplikasi. Urutan sumber (yang belakangan menimpa):
+//
+//  1. default di Default()
+//  2. file YAML opsional dari env CONFIG_FILE (lihat config.example.yaml)
+//  3. file .env opsional (tidak menimpa env yang sudah di-set)
+//  4. environment variable (nama ada di tag env)
+//
+// Config divalidasi saat Load supaya konfigurasi yang salah langsung ketahuan saat start.
+type Config struct {
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	JWT        JWTConfig        `yaml:"jwt"`
+	CORS       CORSConfig       `yaml:"cors"`
+	Mail       MailConfig       `yaml:"mail"`
+	Venue      VenueConfig      `yaml:"venue"`
+	LoginGuard LoginGuardConfig `yaml:"login_guard"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
+	Workers    WorkersConfig    `yaml:"workers"`
+	Log        LogConfig        `yaml:"log"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
+}
+
+type ServerConfig struct {
+	Port            int           `yaml:"port" env:"APP_PORT"`
+	BaseURL         string        `yaml:"base_url" env:"APP_BASE_URL"` // URL frontend untuk link di email
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	RequestTimeout  time.Duration `yaml:"request_timeout" env:"REQUEST_TIMEOUT"` // deadline context request, query yang melewatinya dibatalkan
+	// TrustedProxies adalah IP/CIDR reverse proxy yang header X-Forwarded-For-nya dipercaya
+	// untuk IP client (rate limit, login guard). Kosong = tidak ada, IP client diambil dari koneksi.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"` // dipisah koma di env
+	MaxBodyBytes   int      `yaml:"max_body_bytes" env:"MAX_BODY_BYTES"`   // batas body yang dibaca utuh untuk Idempotency-Key
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"` // 0 = tanpa batas
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"` // 0 = tanpa batas
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"` // total waktu retry koneksi saat start
+}
+
+type JWTConfig struct {
+	KeysDir   string `yaml:"keys_dir" env:"JWT_KEYS_DIR"` // kosong = kunci Ed25519 sementara (development)
+	ActiveKID string `yaml:"active_kid" env:"JWT_ACTIVE_KID"`
+}
+
+// CORSConfig mengatur origin browser yang boleh memanggil API (lihat OriginPattern)
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"` // dipisah koma di env
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE"` // lama browser menyimpan hasil preflight
+}
+
+type MailConfig struct {
+	Driver       string `yaml:"driver" env:"MAILER"` // log atau smtp
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+}
+
+type VenueConfig struct {
+	Timezone string `yaml:"timezone" env:"VENUE_TIMEZONE"` // timezone default venue (nama IANA)
+}
+
+type LoginGuardConfig struct {
+	Backend string `yaml:"backend" env:"LOGIN_LIMITER_BACKEND"` // memory atau postgres
+}
+
+type RateLimitConfig struct {
+	Enabled bool   `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Backend string `yaml:"backend" env:"RATE_LIMIT_BACKEND"` // memory atau postgres
+}
+
+type WorkersConfig struct {
+	SoftDeleteRetentionDays int           `yaml:"soft_delete_retention_days" env:"SOFT_DELETE_RETENTION_DAYS"` // 0 = purge tidak jalan
+	PurgeInterval           time.Duration `yaml:"purge_interval" env:"PURGE_INTERVAL"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`   // debug, info, warn atau error
+	Format string `yaml:"format" env:"LOG_FORMAT"` // json atau text
+}
+
+// TracingConfig memilih exporter trace. Endpoint OTLP dan sampler tetap memakai
+// env standar OpenTelemetry (OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_TRACES_SAMPLER, ...).
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"` // otlp, stdout atau none
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+type MetricsConfig struct {
+	Token string `yaml:"token" env:"METRICS_TOKEN"` // kosong = /metrics tanpa token
+}
+
+// Default mengembalikan konfigurasi default untuk development lokal
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            8080,
+			BaseURL:         "http://localhost:3000",
+			ShutdownTimeout: 5 * time.Second,
+			RequestTimeout:  10 * time.Second,
+			MaxBodyBytes:    1 << 20,
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectTimeout:  60 * time.Second,
+		},
+		CORS:       CORSConfig{AllowedOrigins: []string{"http://localhost:3000"}, MaxAge: 10 * time.Minute},
+		Mail:       MailConfig{Driver: "log", SMTPPort: 587, From: "no-reply@gofutsal.local"},
+		Venue:      VenueConfig{Timezone: "Asia/Jakarta"},
+		LoginGuard: LoginGuardConfig{Backend: "memory"},
+		RateLimit:  RateLimitConfig{Enabled: true, Backend: "memory"},
+		Workers:    WorkersConfig{SoftDeleteRetentionDays: 30, PurgeInterval: 24 * time.Hour},
+		Log:        LogConfig{Level: "info", Format: "json"},
+		Tracing:    TracingConfig{Exporter: "none", ServiceName: "gofutsal"},
+	}
+}
+
+// Load membaca konfigurasi dari default, YAML, .env dan environment, lalu memvalidasinya
+func Load() (*Config, error) {
+	// .env opsional (misalnya di container yang memakai env asli)
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load .env: %w", err)
+	}
+
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadYAML(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadEnv(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func loadYAML(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("load config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// loadEnv mengisi field yang punya tag env dari environment variable yang tidak kosong
+func loadEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := loadEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Env kosong dianggap tidak di-set, sama seperti sebelum ada Config
+		key := t.Field(i).Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		raw := strings.TrimSpace(os.Getenv(key))
+		if raw == "" {
+			continue
+		}
+
+		switch {
+		case field.Type() == durationType:
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return fmt.Errorf("%s: invalid duration %q", key, raw)
+			}
+			field.SetInt(int64(d))
+		case field.Kind() == reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("%s: invalid boolean %q", key, raw)
+			}
+			field.SetBool(b)
+		case field.Kind() == reflect.Int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("%s: invalid number %q", key, raw)
+			}
+			field.SetInt(int64(n))
+		case field.Kind() == reflect.String:
+			field.SetString(raw)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+			var items []string
+			for _, s := range strings.Split(raw, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					items = append(items, s)
+				}
+			}
+			field.Set(reflect.ValueOf(items))
+		default:
+			return fmt.Errorf("%s: unsupported config type %s", key, field.Type())
+		}
+	}
+	return nil
+}
+
+// Validate mengecek semua nilai konfigurasi dan mengembalikan semua kesalahan sekaligus
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Server.Port), "APP_PORT must be between 1 and 65535")
+	u, err := url.Parse(c.Server.BaseURL)
+	check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "APP_BASE_URL must be an absolute http(s) URL")
+	check(c.Server.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+	check(c.Server.RequestTimeout > 0, "REQUEST_TIMEOUT must be positive")
+	check(c.Server.MaxBodyBytes > 0, "MAX_BODY_BYTES must be positive")
+	for _, p := range c.Server.TrustedProxies {
+		check(validIPOrCIDR(p), "TRUSTED_PROXIES: %q is not an IP address or CIDR", p)
+	}
+
+	check(c.Database.Host != "", "DB_HOST is required")
+	check(validPort(c.Database.Port), "DB_PORT must be between 1 and 65535")
+	check(c.Database.User != "", "DB_USER is required")
+	check(c.Database.Name != "", "DB_NAME is required")
+	check(oneOf(c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+		"DB_SSLMODE must be one of disable, allow, prefer, require, verify-ca, verify-full")
+	check(c.Database.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS")
+	check(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME must not be negative")
+	check(c.Database.ConnectTimeout > 0, "DB_CONNECT_TIMEOUT must be positive")
+
+	check(len(c.CORS.AllowedOrigins) > 0, "CORS_ALLOWED_ORIGINS must not be empty")
+	for _, o := range c.CORS.AllowedOrigins {
+		p, err := ParseOriginPattern(o)
+		check(err == nil, "CORS_ALLOWED_ORIGINS: %v", err)
+		check(!(p.Any && c.CORS.AllowCredentials), "CORS_ALLOWED_ORIGINS must not contain * when CORS_ALLOW_CREDENTIALS is true")
+	}
+	check(c.CORS.MaxAge >= 0, "CORS_MAX_AGE must not be negative")
+
+	check(oneOf(c.Mail.Driver, "log", "smtp"), "MAILER must be log or smtp")
+	if c.Mail.Driver == "smtp" {
+		check(c.Mail.SMTPHost != "", "SMTP_HOST is required when MAILER=smtp")
+		check(validPort(c.Mail.SMTPPort), "SMTP_PORT must be between 1 and 65535")
+	}
+	check(strings.Contains(c.Mail.From, "@"), "MAIL_FROM must be an email address")
+
+	_, err = time.LoadLocation(c.Venue.Timezone)
+	check(c.Venue.Timezone != "" && err == nil, "VENUE_TIMEZONE %q is not a valid IANA timezone", c.Venue.Timezone)
+
+	check(oneOf(c.LoginGuard.Backend, "memory", "postgres"), "LOGIN_LIMITER_BACKEND must be memory or postgres")
+	check(oneOf(c.RateLimit.Backend, "memory", "postgres"), "RATE_LIMIT_BACKEND must be memory or postgres")
+
+	check(c.Workers.SoftDeleteRetentionDays >= 0, "SOFT_DELETE_RETENTION_DAYS must not be negative")
+	check(c.Workers.PurgeInterval > 0, "PURGE_INTERVAL must be positive")
+
+	check(oneOf(strings.ToLower(c.Log.Level), "debug", "info", "warn", "error"), "LOG_LEVEL must be debug, info, warn or error")
+	check(oneOf(strings.ToLower(c.Log.Format), "json", "text"), "LOG_FORMAT must be json or text")
+
+	check(oneOf(strings.ToLower(c.Tracing.Exporter), "otlp", "stdout", "none"), "OTEL_TRACES_EXPORTER must be otlp, stdout or none")
+	check(c.Tracing.ServiceName != "", "OTEL_SERVICE_NAME must not be empty")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// DSN membuat connection string PostgreSQL, nilai di-escape supaya password dengan karakter khusus tetap aman
+func (d DatabaseConfig) DSN() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:     "/" + d.Name,
+		RawQuery: url.Values{"sslmode": {d.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+func validIPOrCIDR(s string) bool {
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return true
+	}
+	return net.ParseIP(s) != nil
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func oneOf(v string, allowed ...string) bool {
+	for _, a := range allowed {
+		if v == a {
+			return true
+		}
+	}
+	return false
+}
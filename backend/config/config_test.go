@@ -0,0 +1,186 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv mengosongkan semua env konfigurasi selama test (dikembalikan setelahnya)
+// dan pindah ke direktori kosong supaya .env milik developer tidak ikut terbaca
+func clearEnv(t *testing.T) {
+	t.Helper()
+	keys := []string{"CONFIG_FILE"}
+	var collect func(reflect.Type)
+	collect = func(typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+				collect(f.Type)
+			} else if key := f.Tag.Get("env"); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	collect(reflect.TypeOf(Config{}))
+
+	for _, key := range keys {
+		t.Setenv(key, "")
+		// godotenv tidak menimpa env yang sudah ada walaupun kosong
+		os.Unsetenv(key)
+	}
+	t.Chdir(t.TempDir())
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DB_USER", "gofutsal")
+	t.Setenv("DB_NAME", "gofutsal")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Default()
+	want.Database.User = "gofutsal"
+	want.Database.Name = "gofutsal"
+	if !reflect.DeepEqual(*cfg, want) {
+		t.Errorf("Load() = %+v\nwant %+v", *cfg, want)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	yamlPath := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+  request_timeout: 30s
+database:
+  host: yaml-host
+  name: yaml-db
+  user: yaml-user
+log:
+  level: debug
+cors:
+  allowed_origins: ["https://yaml.example.com"]
+`)
+	writeFile(t, ".env", "APP_PORT=9100\nDB_HOST=dotenv-host\nDB_USER=dotenv-user\n")
+	t.Setenv("CONFIG_FILE", yamlPath)
+	t.Setenv("APP_PORT", "9200")
+	t.Setenv("DB_USER", "  env-user  ")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://gofutsal.id, https://*.gofutsal.id,")
+	t.Setenv("RATE_LIMIT_ENABLED", "false")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"env over .env and YAML", cfg.Server.Port, 9200},
+		{"env is trimmed", cfg.Database.User, "env-user"},
+		{".env over YAML", cfg.Database.Host, "dotenv-host"},
+		{"YAML over default", cfg.Database.Name, "yaml-db"},
+		{"YAML duration", cfg.Server.RequestTimeout, 30 * time.Second},
+		{"YAML string", cfg.Log.Level, "debug"},
+		{"env list", cfg.CORS.AllowedOrigins, []string{"https://gofutsal.id", "https://*.gofutsal.id"}},
+		{"env bool", cfg.RateLimit.Enabled, false},
+		{"default kept", cfg.Database.Port, 5432},
+		{"default kept in same section", cfg.Server.ShutdownTimeout, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		yaml    string
+		wantErr string
+	}{
+		{name: "missing database name", env: map[string]string{"DB_NAME": ""}, wantErr: "DB_NAME is required"},
+		{name: "number", env: map[string]string{"APP_PORT": "80a"}, wantErr: `APP_PORT: invalid number "80a"`},
+		{name: "duration without unit", env: map[string]string{"REQUEST_TIMEOUT": "10"}, wantErr: `REQUEST_TIMEOUT: invalid duration "10"`},
+		{name: "boolean", env: map[string]string{"RATE_LIMIT_ENABLED": "maybe"}, wantErr: `RATE_LIMIT_ENABLED: invalid boolean "maybe"`},
+		{name: "port out of range", env: map[string]string{"APP_PORT": "70000"}, wantErr: "APP_PORT must be between 1 and 65535"},
+		{name: "relative base url", env: map[string]string{"APP_BASE_URL": "gofutsal.id"}, wantErr: "APP_BASE_URL must be an absolute http(s) URL"},
+		{name: "zero timeout", env: map[string]string{"REQUEST_TIMEOUT": "0s"}, wantErr: "REQUEST_TIMEOUT must be positive"},
+		{name: "zero body limit", env: map[string]string{"MAX_BODY_BYTES": "0"}, wantErr: "MAX_BODY_BYTES must be positive"},
+		{name: "trusted proxy", env: map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,proxy.local"}, wantErr: `TRUSTED_PROXIES: "proxy.local" is not an IP address or CIDR`},
+		{name: "ssl mode", env: map[string]string{"DB_SSLMODE": "on"}, wantErr: "DB_SSLMODE must be one of"},
+		{name: "idle over open conns", env: map[string]string{"DB_MAX_OPEN_CONNS": "5", "DB_MAX_IDLE_CONNS": "10"}, wantErr: "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS"},
+		{name: "wildcard origin with credentials", env: map[string]string{"CORS_ALLOWED_ORIGINS": "*", "CORS_ALLOW_CREDENTIALS": "true"}, wantErr: "must not contain * when CORS_ALLOW_CREDENTIALS is true"},
+		{name: "smtp without host", env: map[string]string{"MAILER": "smtp"}, wantErr: "SMTP_HOST is required when MAILER=smtp"},
+		{name: "timezone", env: map[string]string{"VENUE_TIMEZONE": "Asia/Bandung"}, wantErr: `VENUE_TIMEZONE "Asia/Bandung" is not a valid IANA timezone`},
+		{name: "limiter backend", env: map[string]string{"RATE_LIMIT_BACKEND": "redis"}, wantErr: "RATE_LIMIT_BACKEND must be memory or postgres"},
+		{name: "log level", env: map[string]string{"LOG_LEVEL": "verbose"}, wantErr: "LOG_LEVEL must be debug, info, warn or error"},
+		{name: "unknown YAML field", yaml: "server:\n  prot: 8080\n", wantErr: "field prot not found"},
+		{name: "YAML type", yaml: "server:\n  port: eighty\n", wantErr: "parse config file"},
+		{name: "missing config file", env: map[string]string{"CONFIG_FILE": "does-not-exist.yaml"}, wantErr: "load config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("DB_USER", "gofutsal")
+			t.Setenv("DB_NAME", "gofutsal")
+			if tt.yaml != "" {
+				t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", tt.yaml))
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Port = 0
+	cfg.Log.Format = "xml"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want error")
+	}
+	for _, want := range []string{"APP_PORT", "DB_USER", "DB_NAME", "LOG_FORMAT"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestDSNEscapesValues(t *testing.T) {
+	d := DatabaseConfig{Host: "db", Port: 5432, User: "app", Password: "p@ss/word", Name: "gofutsal", SSLMode: "require"}
+	want := "postgres://app:p%40ss%2Fword@db:5432/gofutsal?sslmode=require"
+	if got := d.DSN(); got != want {
+		t.Errorf("DSN() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OriginPattern adalah satu origin CORS yang diizinkan: exact ("https://gofutsal.id")
+// atau wildcard subdomain ("https://*.gofutsal.id", cocok untuk a.gofutsal.id dan
+// a.b.gofutsal.id tetapi tidak untuk gofutsal.id sendiri). "*" berarti semua origin.
+type OriginPattern struct {
+	Any      bool
+	Scheme   string
+	Host     string // termasuk port jika ada, tanpa "*." untuk wildcard
+	Wildcard bool
+}
+
+// ParseOriginPattern membaca origin dari konfigurasi CORS_ALLOWED_ORIGINS
+func ParseOriginPattern(s string) (OriginPattern, error) {
+	if s == "*" {
+		return OriginPattern{Any: true}, nil
+	}
+
+	scheme, host, ok := strings.Cut(strings.ToLower(s), "://")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return OriginPattern{}, fmt.Errorf("origin %q must start with http:// or https://", s)
+	}
+	if host == "" || strings.ContainsAny(host, "/?#") {
+		return OriginPattern{}, fmt.Errorf("origin %q must not contain a path", s)
+	}
+
+	p := OriginPattern{Scheme: scheme, Host: host}
+	if rest, found := strings.CutPrefix(host, "*."); found {
+		p.Wildcard = true
+		p.Host = rest
+	}
+	if p.Host == "" || strings.Contains(p.Host, "*") {
+		return OriginPattern{}, fmt.Errorf("origin %q may only use a wildcard as the first label (https://*.example.com)", s)
+	}
+	return p, nil
+}
+
+// Match mengecek apakah header Origin request cocok dengan pattern
+func (p OriginPattern) Match(origin string) bool {
+	if p.Any {
+		return true
+	}
+
+	scheme, host, ok := strings.Cut(strings.ToLower(origin), "://")
+	if !ok || scheme != p.Scheme {
+		return false
+	}
+	if p.Wildcard {
+		return strings.HasSuffix(host, "."+p.Host) && len(host) > len(p.Host)+1
+	}
+	return host == p.Host
+}
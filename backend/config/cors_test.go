@@ -0,0 +1,87 @@
+package config
+
+import "testing"
+
+func TestParseOriginPattern(t *testing.T) {
+	tests := []struct {
+		origin  string
+		want    OriginPattern
+		wantErr bool
+	}{
+		{origin: "*", want: OriginPattern{Any: true}},
+		{origin: "https://gofutsal.id", want: OriginPattern{Scheme: "https", Host: "gofutsal.id"}},
+		{origin: "HTTP://Localhost:3000", want: OriginPattern{Scheme: "http", Host: "localhost:3000"}},
+		{origin: "https://*.example.com", want: OriginPattern{Scheme: "https", Host: "example.com", Wildcard: true}},
+		{origin: "https://*.example.com:8443", want: OriginPattern{Scheme: "https", Host: "example.com:8443", Wildcard: true}},
+		{origin: "example.com", wantErr: true},
+		{origin: "ftp://example.com", wantErr: true},
+		{origin: "https://", wantErr: true},
+		{origin: "https://example.com/app", wantErr: true},
+		{origin: "https://*.", wantErr: true},
+		{origin: "https://a.*.example.com", wantErr: true},
+		{origin: "https://*example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			got, err := ParseOriginPattern(tt.origin)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseOriginPattern(%q) = %+v, want error", tt.origin, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOriginPattern(%q) error: %v", tt.origin, err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseOriginPattern(%q) = %+v, want %+v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOriginPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		origin  string
+		want    bool
+	}{
+		{"*", "https://anything.example", true},
+
+		// Exact
+		{"https://gofutsal.id", "https://gofutsal.id", true},
+		{"https://gofutsal.id", "https://GoFutsal.id", true},
+		{"https://gofutsal.id", "http://gofutsal.id", false},
+		{"https://gofutsal.id", "https://www.gofutsal.id", false},
+		{"https://gofutsal.id", "https://gofutsal.id.evil.com", false},
+
+		// Wildcard subdomain
+		{"https://*.example.com", "https://app.example.com", true},
+		{"https://*.example.com", "https://a.b.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://evil-example.com", false},
+		{"https://*.example.com", "https://.example.com", false},
+		{"https://*.example.com", "http://app.example.com", false},
+
+		// Port
+		{"http://localhost:3000", "http://localhost:3000", true},
+		{"http://localhost:3000", "http://localhost:3001", false},
+		{"http://localhost:3000", "http://localhost", false},
+		{"https://*.example.com", "https://app.example.com:8443", false},
+		{"https://*.example.com:8443", "https://app.example.com:8443", true},
+		{"https://*.example.com:8443", "https://app.example.com", false},
+
+		{"https://gofutsal.id", "null", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.origin, func(t *testing.T) {
+			p, err := ParseOriginPattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Match(tt.origin); got != tt.want {
+				t.Fatalf("%q.Match(%q) = %v, want %v", tt.pattern, tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,43 +1,64 @@
 package config
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/joho/godotenv"
+	"github.com/HenryKristofani/GoFutsal/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
 var DB *sql.DB
 
-func ConnectDB() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
+// Jeda retry koneksi saat start, dimulai dari connectBackoff dan digandakan sampai maxConnectBackoff
+const (
+	connectBackoff    = 500 * time.Millisecond
+	maxConnectBackoff = 10 * time.Second
+	pingTimeout       = 5 * time.Second
+)
 
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_SSLMODE"),
-	)
-
-	db, err := sql.Open("pgx", dsn)
+// ConnectDB membuka koneksi PostgreSQL sesuai konfigurasi. Jika database belum siap
+// (misalnya container Postgres baru start), koneksi dicoba ulang dengan backoff
+// sampai ConnectTimeout habis.
+func ConnectDB(cfg DatabaseConfig) error {
+	connConfig, err := pgx.ParseConfig(cfg.DSN())
 	if err != nil {
-		log.Fatal("Database connection error:", err)
+		return fmt.Errorf("database config: %w", err)
 	}
+	// Setiap query dicatat sebagai span OpenTelemetry
+	connConfig.Tracer = tracing.QueryTracer{}
+	db := stdlib.OpenDB(*connConfig)
 
-	err = db.Ping()
-	if err != nil {
-		log.Fatal("Database unreachable:", err)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	deadline := time.Now().Add(cfg.ConnectTimeout)
+	backoff := connectBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			break
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			db.Close()
+			return fmt.Errorf("database unreachable after %d attempts: %w", attempt, err)
+		}
+
+		slog.Warn("database not ready, retrying", "attempt", attempt, "retry_in", backoff.String(), "error", err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
 	}
 
 	DB = db
-	fmt.Println("✅ Connected to PostgreSQL database")
-}
\ No newline at end of file
+	slog.Info("connected to PostgreSQL database", "host", cfg.Host, "database", cfg.Name,
+		"max_open_conns", cfg.MaxOpenConns, "max_idle_conns", cfg.MaxIdleConns)
+	return nil
+}
@@ -0,0 +1,15 @@
+-- Akun baru harus verifikasi email sebelum bisa booking
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'users' AND column_name = 'email_verified_at'
+    ) THEN
+        ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+        -- Akun yang sudah ada dianggap terverifikasi
+        UPDATE users SET email_verified_at = NOW();
+    END IF;
+END $$;
+
+-- Waktu email verifikasi terakhir dikirim, untuk membatasi resend
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMPTZ;
@@ -0,0 +1,18 @@
+-- TOTP 2FA (RFC 6238). totp_secret terisi saat enroll,
+-- 2FA baru aktif setelah kode pertama diverifikasi (totp_enabled_at).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMPTZ;
+-- Langkah waktu TOTP terakhir yang dipakai, supaya kode tidak bisa dipakai ulang
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- Kode pemulihan sekali pakai, disimpan dalam bentuk hash SHA-256
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Admin bisa mewajibkan 2FA untuk role tertentu
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS mfa_required BOOLEAN NOT NULL DEFAULT FALSE;
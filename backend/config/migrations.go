@@ -1,57 +1,163 @@
 package config
 
 import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"path/filepath"
 )
 
-// RunMigrations executes all SQL migration files in the config directory
+// migrationFiles adalah file SQL di folder config, dijalankan berurutan
+var migrationFiles = []string{
+	"users.sql",
+	"add_user_id_to_bookings.sql",
+	"ensure_username_unique.sql",
+	"booking_timestamps.sql",
+	"venues.sql",
+	"rbac.sql",
+	"auth_tokens.sql",
+	"email_verification.sql",
+	"login_security.sql",
+	"mfa.sql",
+	"case_insensitive_identifiers.sql",
+	"api_keys.sql",
+	"sessions.sql",
+	"audit_log.sql",
+	"soft_delete.sql",
+	"rate_limit.sql",
+	"idempotency_keys.sql",
+}
+
+// schemaMigrationsTable mencatat migrasi yang sudah berhasil dijalankan beserta
+// checksum isinya. Migrasi dengan checksum yang sama tidak dijalankan ulang.
+const schemaMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		filename VARCHAR(255) PRIMARY KEY,
+		checksum CHAR(64) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)
+`
+
+// expectedMigrations berisi checksum setiap file migrasi yang dibaca saat startup,
+// kosong jika file tidak bisa dibaca (file SQL kosong tidak dicatat). Dipakai readiness check.
+var expectedMigrations = map[string]string{}
+
+// RunMigrations executes all SQL migration files in the config directory.
+// Setiap file dijalankan bersama pencatatannya di schema_migrations dalam satu transaksi;
+// migrasi pertama yang gagal menghentikan proses dan error-nya dikembalikan.
 func RunMigrations() error {
-	migrationFiles := []string{
-		"users.sql",
-		"add_user_id_to_bookings.sql",
-		"ensure_username_unique.sql",
+	if _, err := DB.Exec(schemaMigrationsTable); err != nil {
+		return err
 	}
 
 	for _, filename := range migrationFiles {
 		filePath := filepath.Join("config", filename)
+		expectedMigrations[filename] = ""
 
 		// Read SQL file
 		sqlBytes, err := ioutil.ReadFile(filePath)
 		if err != nil {
-			log.Printf("Warning: Could not read migration file %s: %v", filename, err)
-			continue
+			return fmt.Errorf("read migration %s: %w", filename, err)
 		}
 
 		sqlQuery := string(sqlBytes)
 		if sqlQuery == "" {
+			delete(expectedMigrations, filename)
 			continue
 		}
 
-		// Execute migration
-		log.Printf("Running migration: %s", filename)
-		_, err = DB.Exec(sqlQuery)
-		if err != nil {
-			log.Printf("Error running migration %s: %v", filename, err)
-			// Don't return error, continue with other migrations
+		sum := sha256.Sum256(sqlBytes)
+		checksum := hex.EncodeToString(sum[:])
+		expectedMigrations[filename] = checksum
+
+		var applied string
+		err = DB.QueryRow("SELECT checksum FROM schema_migrations WHERE filename = $1", filename).Scan(&applied)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("check migration %s: %w", filename, err)
+		}
+		if applied == checksum {
+			slog.Debug("migration already applied", "file", filename)
 			continue
 		}
 
-		log.Printf("Successfully applied migration: %s", filename)
+		// Execute migration
+		slog.Debug("running migration", "file", filename)
+		if err := applyMigration(filename, sqlQuery, checksum); err != nil {
+			return fmt.Errorf("migration %s: %w", filename, err)
+		}
+
+		slog.Info("migration applied", "file", filename)
+	}
+
+	return nil
+}
+
+// applyMigration menjalankan satu file migrasi dan mencatat checksum-nya dalam satu transaksi
+func applyMigration(filename, sqlQuery, checksum string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(sqlQuery); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO schema_migrations (filename, checksum) VALUES ($1, $2)
+		ON CONFLICT (filename) DO UPDATE SET checksum = EXCLUDED.checksum, applied_at = NOW()
+	`, filename, checksum)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrationsUpToDate mengecek bahwa semua file migrasi sudah tercatat di
+// schema_migrations dengan checksum yang sama seperti saat startup
+func MigrationsUpToDate(ctx context.Context) error {
+	if len(expectedMigrations) == 0 {
+		return fmt.Errorf("migrations have not been run")
+	}
+
+	rows, err := DB.QueryContext(ctx, "SELECT filename, checksum FROM schema_migrations")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	applied := map[string]string{}
+	for rows.Next() {
+		var filename, checksum string
+		if err := rows.Scan(&filename, &checksum); err != nil {
+			return err
+		}
+		applied[filename] = checksum
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
+	for filename, checksum := range expectedMigrations {
+		if checksum == "" || applied[filename] != checksum {
+			return fmt.Errorf("migration %s is not applied", filename)
+		}
+	}
 	return nil
 }
 
-// CheckAndRunMigrations runs migrations and handles errors gracefully
-func CheckAndRunMigrations() {
-	fmt.Println("🔄 Running database migrations...")
+// CheckAndRunMigrations runs migrations and returns the first failure
+func CheckAndRunMigrations() error {
+	slog.Info("running database migrations")
 
 	if err := RunMigrations(); err != nil {
-		log.Printf("Migration error: %v", err)
+		return err
 	}
 
-	fmt.Println("✅ Database migrations completed")
+	slog.Info("database migrations completed")
+	return nil
 }
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// withMigrations menjalankan test dari root backend dengan daftar migrasi dan DB mock sendiri
+func withMigrations(t *testing.T, files ...string) sqlmock.Sqlmock {
+	t.Helper()
+	t.Chdir("..")
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevDB, prevFiles, prevExpected := DB, migrationFiles, expectedMigrations
+	DB, migrationFiles, expectedMigrations = db, files, map[string]string{}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+		DB, migrationFiles, expectedMigrations = prevDB, prevFiles, prevExpected
+	})
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	return mock
+}
+
+func checksumOf(t *testing.T, filename string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("config", filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRunMigrationsRecordsAppliedFiles(t *testing.T) {
+	mock := withMigrations(t, "users.sql", "add_user_id_to_bookings.sql")
+	users := checksumOf(t, "users.sql")
+	bookings := checksumOf(t, "add_user_id_to_bookings.sql")
+
+	// users.sql sudah tercatat dengan checksum yang sama, jadi dilewati
+	mock.ExpectQuery(`SELECT checksum FROM schema_migrations`).WithArgs("users.sql").
+		WillReturnRows(sqlmock.NewRows([]string{"checksum"}).AddRow(users))
+	mock.ExpectQuery(`SELECT checksum FROM schema_migrations`).WithArgs("add_user_id_to_bookings.sql").
+		WillReturnRows(sqlmock.NewRows([]string{"checksum"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`ADD COLUMN IF NOT EXISTS user_id`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs("add_user_id_to_bookings.sql", bookings).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(`SELECT filename, checksum FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"filename", "checksum"}).
+			AddRow("users.sql", users).
+			AddRow("add_user_id_to_bookings.sql", bookings))
+	if err := MigrationsUpToDate(t.Context()); err != nil {
+		t.Errorf("MigrationsUpToDate() = %v, want nil", err)
+	}
+}
+
+func TestRunMigrationsStopsOnFailure(t *testing.T) {
+	mock := withMigrations(t, "add_user_id_to_bookings.sql", "users.sql")
+	failure := errors.New(`column "user_id" of relation "bookings" already exists`)
+
+	mock.ExpectQuery(`SELECT checksum FROM schema_migrations`).WithArgs("add_user_id_to_bookings.sql").
+		WillReturnRows(sqlmock.NewRows([]string{"checksum"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`ALTER TABLE bookings`).WillReturnError(failure)
+	mock.ExpectRollback()
+
+	// Migrasi yang gagal tidak dicatat dan file berikutnya tidak dijalankan
+	err := RunMigrations()
+	if !errors.Is(err, failure) {
+		t.Fatalf("RunMigrations() = %v, want %v", err, failure)
+	}
+
+	mock.ExpectQuery(`SELECT filename, checksum FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"filename", "checksum"}))
+	if err := MigrationsUpToDate(t.Context()); err == nil {
+		t.Error("MigrationsUpToDate() = nil, want error after a failed migration")
+	}
+}
+
+func TestRunMigrationsMissingFile(t *testing.T) {
+	withMigrations(t, "does_not_exist.sql")
+
+	if err := RunMigrations(); err == nil {
+		t.Error("RunMigrations() = nil, want error for a missing file")
+	}
+}
@@ -0,0 +1,86 @@
+-- Role dan permission disimpan di database supaya bisa diatur oleh admin
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    built_in BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description, built_in) VALUES
+    ('admin', 'Akses penuh ke semua venue dan pengaturan', TRUE),
+    ('venue_manager', 'Mengelola lapangan, booking dan staff di venue yang ditugaskan', TRUE),
+    ('cashier', 'Check-in booking dan menerima pembayaran di venue yang ditugaskan', TRUE),
+    ('client', 'Pelanggan yang melakukan booking', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('venues:all', 'Mengakses semua venue tanpa penugasan'),
+    ('venues:admin', 'Membuat, menghapus venue dan menugaskan staff'),
+    ('venues:write', 'Memperbarui detail venue'),
+    ('courts:write', 'Membuat, mengubah (termasuk harga) dan menghapus lapangan'),
+    ('bookings:read_all', 'Melihat semua booking di venue'),
+    ('bookings:cancel', 'Membatalkan booking pelanggan'),
+    ('bookings:checkin', 'Check-in booking saat pelanggan datang'),
+    ('payments:create', 'Mencatat pembayaran booking'),
+    ('payments:read', 'Melihat pembayaran booking'),
+    ('users:manage', 'Melihat dan mengelola akun user'),
+    ('roles:manage', 'Mengelola role, permission dan role user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('venue_manager', 'venues:write'),
+    ('venue_manager', 'courts:write'),
+    ('venue_manager', 'bookings:read_all'),
+    ('venue_manager', 'bookings:cancel'),
+    ('venue_manager', 'bookings:checkin'),
+    ('venue_manager', 'payments:create'),
+    ('venue_manager', 'payments:read'),
+    ('cashier', 'bookings:read_all'),
+    ('cashier', 'bookings:checkin'),
+    ('cashier', 'payments:create'),
+    ('cashier', 'payments:read')
+ON CONFLICT DO NOTHING;
+
+-- users.role sekarang mengacu ke tabel roles, bukan CHECK constraint
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50);
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'users_role_fkey'
+    ) THEN
+        ALTER TABLE users ADD CONSTRAINT users_role_fkey
+            FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+    END IF;
+END $$;
+
+-- Check-in dan pembayaran booking
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMPTZ;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_in_by INTEGER REFERENCES users(id);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    method VARCHAR(20) NOT NULL,
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    received_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_payments_booking_id ON payments(booking_id);
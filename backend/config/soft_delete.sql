@@ -0,0 +1,35 @@
+-- Soft delete: baris yang dihapus hanya ditandai deleted_at dan disembunyikan dari query,
+-- supaya booking lama tetap punya lapangan dan user. Baris dihapus permanen oleh
+-- purge job setelah masa retensi (SOFT_DELETE_RETENTION_DAYS).
+ALTER TABLE courts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_courts_deleted_at ON courts(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_bookings_deleted_at ON bookings(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Booking yang sudah dihapus tidak boleh menghalangi booking baru di jam yang sama
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'bookings_no_overlap'
+          AND pg_get_constraintdef(oid) NOT LIKE '%deleted_at%'
+    ) THEN
+        ALTER TABLE bookings DROP CONSTRAINT bookings_no_overlap;
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'bookings_no_overlap'
+    ) THEN
+        BEGIN
+            ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+                EXCLUDE USING gist (court_id WITH =, tstzrange(start_at, end_at) WITH &&)
+                WHERE (deleted_at IS NULL);
+        EXCEPTION WHEN exclusion_violation THEN
+            RAISE WARNING 'Existing bookings overlap, bookings_no_overlap not added';
+        END;
+    END IF;
+END $$;
@@ -0,0 +1,39 @@
+-- Venue (lokasi) yang memiliki beberapa lapangan
+CREATE TABLE IF NOT EXISTS venues (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta',
+    opening_time VARCHAR(5) NOT NULL DEFAULT '08:00',
+    closing_time VARCHAR(5) NOT NULL DEFAULT '23:00',
+    phone VARCHAR(30) NOT NULL DEFAULT '',
+    email VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE courts ADD COLUMN IF NOT EXISTS venue_id INTEGER REFERENCES venues(id);
+CREATE INDEX IF NOT EXISTS idx_courts_venue_id ON courts(venue_id);
+
+-- Buat venue dari courts.location untuk lapangan yang belum punya venue
+INSERT INTO venues (name, address, timezone)
+SELECT DISTINCT c.location, c.location, c.timezone
+FROM courts c
+WHERE c.venue_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM venues v WHERE v.name = c.location);
+
+UPDATE courts c
+SET venue_id = v.id
+FROM venues v
+WHERE c.venue_id IS NULL AND v.name = c.location;
+
+-- Staff (venue manager, kasir) hanya boleh mengelola venue yang ditugaskan
+CREATE TABLE IF NOT EXISTS venue_managers (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    venue_id INTEGER NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, venue_id)
+);
+CREATE INDEX IF NOT EXISTS idx_venue_managers_venue_id ON venue_managers(venue_id);
+
+-- Role venue_manager didaftarkan di rbac.sql
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// canAccessAllVenues mengecek apakah role user (atau API key) boleh mengakses semua venue tanpa penugasan
+func canAccessAllVenues(c *gin.Context) (bool, error) {
+	return rbac.ContextHasPermission(c, rbac.VenuesAll)
+}
+
+// authorizeVenue memastikan user yang login boleh mengelola venue tertentu.
+// Role dengan permission venues:all (admin) boleh mengelola semua venue,
+// staff lain (venue manager, kasir) hanya venue yang ditugaskan.
+// API key hanya boleh mengakses venue yang didaftarkan pada key.
+// venueID nil berarti lapangan belum punya venue dan hanya admin yang boleh mengelola.
+func authorizeVenue(c *gin.Context, venueID *int) error {
+	ctx := c.Request.Context()
+	all, err := canAccessAllVenues(c)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if all {
+		return nil
+	}
+	if venueID == nil {
+		return apperror.Forbidden("Only admins can manage courts without a venue")
+	}
+
+	if key := apikey.FromContext(c); key != nil {
+		if !key.VenueIDs[*venueID] {
+			return apperror.Forbidden("API key is not allowed to access this venue")
+		}
+		return nil
+	}
+
+	var manages bool
+	err = config.DB.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM venue_managers WHERE user_id = $1 AND venue_id = $2)",
+		c.GetInt("user_id"), *venueID,
+	).Scan(&manages)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if !manages {
+		return apperror.Forbidden("You do not manage this venue")
+	}
+	return nil
+}
+
+// apiKeyID mengembalikan ID API key request, nil jika request memakai JWT user
+func apiKeyID(c *gin.Context) *int {
+	if key := apikey.FromContext(c); key != nil {
+		return &key.ID
+	}
+	return nil
+}
+
+// courtVenueID mengambil venue_id dari lapangan
+func courtVenueID(ctx context.Context, courtID string) (*int, error) {
+	var venueID sql.NullInt64
+	err := config.DB.QueryRowContext(ctx, "SELECT venue_id FROM courts WHERE id = $1 AND deleted_at IS NULL", courtID).Scan(&venueID)
+	if err != nil {
+		return nil, apperror.FromDB(err, "Court not found")
+	}
+	if !venueID.Valid {
+		return nil, nil
+	}
+	id := int(venueID.Int64)
+	return &id, nil
+}
+
+// bookingVenueID mengambil venue_id dari lapangan yang dibooking
+func bookingVenueID(ctx context.Context, bookingID string) (*int, error) {
+	var venueID sql.NullInt64
+	err := config.DB.QueryRowContext(ctx, `
+		SELECT c.venue_id FROM bookings b JOIN courts c ON c.id = b.court_id
+		WHERE b.id = $1 AND b.deleted_at IS NULL
+	`, bookingID).Scan(&venueID)
+	if err != nil {
+		return nil, apperror.FromDB(err, "Booking not found")
+	}
+	if !venueID.Valid {
+		return nil, nil
+	}
+	id := int(venueID.Int64)
+	return &id, nil
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/audit"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/HenryKristofani/GoFutsal/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAPIKeyRateLimit adalah limit request per menit jika tidak diisi
+const defaultAPIKeyRateLimit = 60
+
+// APIKeyCreatedResponse berisi key lengkap yang hanya ditampilkan sekali saat dibuat
+type APIKeyCreatedResponse struct {
+	models.APIKey
+	Key string `json:"key" example:"gfk_1a2b3c4d_..."`
+}
+
+// GetAPIKeys godoc
+// @Summary      Get API keys
+// @Description  Menampilkan semua API key beserta scope dan waktu terakhir dipakai (permission apikeys:manage)
+// @Tags         API Keys
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.APIKey
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /api/admin/api-keys [get]
+func GetAPIKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT id, name, prefix, rate_limit_per_minute, created_by, created_at, expires_at, last_used_at, last_used_ip, revoked_at
+		FROM api_keys ORDER BY id
+	`)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	byID := map[int]int{}
+	for rows.Next() {
+		var k models.APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.RateLimitPerMinute, &k.CreatedBy, &k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.LastUsedIP, &k.RevokedAt); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		k.Prefix = apikey.Prefix + k.Prefix
+		k.Permissions = []string{}
+		k.VenueIDs = []int{}
+		byID[k.ID] = len(keys)
+		keys = append(keys, k)
+	}
+
+	permissions, err := config.DB.QueryContext(ctx, "SELECT api_key_id, permission FROM api_key_permissions ORDER BY permission")
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer permissions.Close()
+	for permissions.Next() {
+		var id int
+		var p string
+		if err := permissions.Scan(&id, &p); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		if i, ok := byID[id]; ok {
+			keys[i].Permissions = append(keys[i].Permissions, p)
+		}
+	}
+
+	venues, err := config.DB.QueryContext(ctx, "SELECT api_key_id, venue_id FROM api_key_venues ORDER BY venue_id")
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer venues.Close()
+	for venues.Next() {
+		var id, venueID int
+		if err := venues.Scan(&id, &venueID); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		if i, ok := byID[id]; ok {
+			keys[i].VenueIDs = append(keys[i].VenueIDs, venueID)
+		}
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey godoc
+// @Summary      Create API key
+// @Description  Membuat API key untuk kiosk atau partner (permission apikeys:manage).
+// @Description  Permission yang diberikan harus dimiliki pembuat, dan venue harus bisa diakses pembuat.
+// @Description  Key lengkap hanya ditampilkan sekali di response ini.
+// @Tags         API Keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        api_key  body  dto.APIKeyRequest  true  "API Key Data"
+// @Success      201  {object}  APIKeyCreatedResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /api/admin/api-keys [post]
+func CreateAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "expires_at", Message: "expires_at must be in the future"}))
+		return
+	}
+	if req.RateLimitPerMinute == 0 {
+		req.RateLimitPerMinute = defaultAPIKeyRateLimit
+	}
+
+	// Pembuat key tidak boleh memberi akses melebihi aksesnya sendiri
+	for _, p := range req.Permissions {
+		allowed, err := rbac.ContextHasPermission(c, p)
+		if err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		if !allowed {
+			c.Error(apperror.Forbidden("You cannot grant a permission you do not have: " + p))
+			return
+		}
+	}
+	for _, venueID := range req.VenueIDs {
+		id := venueID
+		if err := authorizeVenue(c, &id); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	raw, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	key := models.APIKey{
+		Name:               req.Name,
+		Prefix:             apikey.Prefix + prefix,
+		Permissions:        []string{},
+		VenueIDs:           []int{},
+		RateLimitPerMinute: req.RateLimitPerMinute,
+		CreatedBy:          optionalID(c.GetInt("user_id")),
+		ExpiresAt:          req.ExpiresAt,
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, rate_limit_per_minute, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, key.Name, prefix, hash, key.RateLimitPerMinute, key.CreatedBy, key.ExpiresAt).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "API key not found"))
+		return
+	}
+
+	for _, p := range req.Permissions {
+		res, err := tx.ExecContext(ctx, "INSERT INTO api_key_permissions (api_key_id, permission) VALUES ($1, $2) ON CONFLICT DO NOTHING", key.ID, p)
+		if err != nil {
+			if appErr := apperror.FromDB(err, ""); appErr.Code == apperror.CodeConflict {
+				c.Error(apperror.Validation(apperror.FieldError{Field: "permissions", Message: "unknown permission " + p}))
+				return
+			}
+			c.Error(apperror.Internal(err))
+			return
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			key.Permissions = append(key.Permissions, p)
+		}
+	}
+	for _, venueID := range req.VenueIDs {
+		res, err := tx.ExecContext(ctx, "INSERT INTO api_key_venues (api_key_id, venue_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", key.ID, venueID)
+		if err != nil {
+			if appErr := apperror.FromDB(err, ""); appErr.Code == apperror.CodeConflict {
+				c.Error(apperror.Validation(apperror.FieldError{Field: "venue_ids", Message: "unknown venue " + strconv.Itoa(venueID)}))
+				return
+			}
+			c.Error(apperror.Internal(err))
+			return
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			key.VenueIDs = append(key.VenueIDs, venueID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if after, err := json.Marshal(key); err == nil {
+		audit.Record(c, audit.ActionCreate, audit.EntityAPIKey, strconv.Itoa(key.ID), nil, after)
+	}
+
+	c.JSON(http.StatusCreated, APIKeyCreatedResponse{APIKey: key, Key: raw})
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke API key
+// @Description  Mencabut API key, request berikutnya dengan key ini ditolak (permission apikeys:manage)
+// @Tags         API Keys
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "API Key ID"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/api-keys/{id} [delete]
+func RevokeAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	var before, after []byte
+	err := config.DB.QueryRowContext(ctx, `
+		UPDATE api_keys k SET revoked_at = COALESCE(old.revoked_at, NOW())
+		FROM api_keys old
+		WHERE k.id = $1 AND old.id = k.id
+		RETURNING to_jsonb(old), to_jsonb(k)
+	`, c.Param("id")).Scan(&before, &after)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "API key not found"))
+		return
+	}
+	audit.Record(c, audit.ActionRevoke, audit.EntityAPIKey, c.Param("id"), before, after)
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
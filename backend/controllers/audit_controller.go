@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditLog godoc
+// @Summary      Get audit log
+// @Description  Menampilkan audit perubahan lapangan, booking, pembayaran dan user terbaru (permission audit:read)
+// @Tags         Audit
+// @Produce      json
+// @Security     BearerAuth
+// @Param        actor_user_id  query  int     false  "Filter user pelaku"
+// @Param        action         query  string  false  "Filter action (create, update, delete, restore, password_change, checkin)"
+// @Param        entity_type    query  string  false  "Filter jenis entity (court, booking, payment, user, role, api_key)"
+// @Param        entity_id      query  string  false  "Filter ID entity"
+// @Param        from           query  string  false  "Sejak waktu (RFC 3339)"
+// @Param        to             query  string  false  "Sampai waktu (RFC 3339)"
+// @Param        limit          query  int     false  "Jumlah data (default 100, maksimal 500)"
+// @Success      200  {array}   models.AuditEntry
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /api/admin/audit [get]
+func GetAuditLog(c *gin.Context) {
+	ctx := c.Request.Context()
+	limit := 100
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 500 {
+			c.Error(apperror.Validation(apperror.FieldError{Field: "limit", Message: "limit must be between 1 and 500"}))
+			return
+		}
+		limit = n
+	}
+
+	actorUserID := 0
+	if v := c.Query("actor_user_id"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.Error(apperror.Validation(apperror.FieldError{Field: "actor_user_id", Message: "actor_user_id must be a positive number"}))
+			return
+		}
+		actorUserID = n
+	}
+
+	from, err := timeQuery(c, "from")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := timeQuery(c, "to")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT id, actor_user_id, actor_api_key_id, actor, action, entity_type, entity_id, before, after, ip_address, request_id, created_at
+		FROM audit_log
+		WHERE ($1 = 0 OR actor_user_id = $1)
+		  AND ($2 = '' OR action = $2)
+		  AND ($3 = '' OR entity_type = $3)
+		  AND ($4 = '' OR entity_id = $4)
+		  AND ($5::TIMESTAMPTZ IS NULL OR created_at >= $5)
+		  AND ($6::TIMESTAMPTZ IS NULL OR created_at < $6)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7
+	`, actorUserID, c.Query("action"), c.Query("entity_type"), c.Query("entity_id"), from, to, limit)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.ActorUserID, &e.ActorAPIKeyID, &e.Actor, &e.Action, &e.EntityType, &e.EntityID, &before, &after, &e.IPAddress, &e.RequestID, &e.CreatedAt); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		e.Before, e.After = before, after
+		entries = append(entries, e)
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// timeQuery membaca query parameter waktu RFC 3339, nil jika tidak diisi
+func timeQuery(c *gin.Context, name string) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, apperror.Validation(apperror.FieldError{Field: name, Message: name + " must be an RFC 3339 timestamp"})
+	}
+	return &t, nil
+}
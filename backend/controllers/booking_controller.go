@@ -1,38 +1,50 @@
 package controllers
 
 import (
+	"context"
+	"database/sql"
 	"net/http"
-	// "strconv"
+	"strconv"
+	"time"
 
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/audit"
 	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/metrics"
 	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/HenryKristofani/GoFutsal/venuetime"
 	"github.com/gin-gonic/gin"
 )
 
 // GET /bookings
 // GetBookings godoc
-// @Summary      Get all bookings
-// @Description  Menampilkan semua data booking
+// @Summary      Get my bookings
+// @Description  Menampilkan booking milik user yang login. Booking user lain lewat /api/admin/bookings.
 // @Tags         Bookings
 // @Produce      json
+// @Security     BearerAuth
 // @Success      200  {array}  models.Booking
 // @Router       /api/bookings [get]
 func GetBookings(c *gin.Context) {
-	rows, err := config.DB.Query(`
-		SELECT id, court_id, user_id, customer_name, booking_date, start_time, end_time, total_price 
-		FROM bookings ORDER BY booking_date DESC
-	`)
+	ctx := c.Request.Context()
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT b.id, b.court_id, COALESCE(b.user_id, 0), b.customer_name, b.start_at, b.end_at, c.timezone, b.total_price, b.checked_in_at
+		FROM bookings b JOIN courts c ON c.id = b.court_id
+		WHERE b.user_id = $1 AND b.deleted_at IS NULL
+		ORDER BY b.start_at DESC
+	`, c.GetInt("user_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.Internal(err))
 		return
 	}
 	defer rows.Close()
 
-	var bookings []models.Booking
+	bookings := []models.Booking{}
 	for rows.Next() {
 		var b models.Booking
-		if err := rows.Scan(&b.ID, &b.CourtID, &b.UserID, &b.CustomerName, &b.BookingDate, &b.StartTime, &b.EndTime, &b.TotalPrice); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := rows.Scan(&b.ID, &b.CourtID, &b.UserID, &b.CustomerName, &b.StartAt, &b.EndAt, &b.Timezone, &b.TotalPrice, &b.CheckedInAt); err != nil {
+			c.Error(apperror.Internal(err))
 			return
 		}
 		bookings = append(bookings, b)
@@ -44,27 +56,30 @@ func GetBookings(c *gin.Context) {
 // GET /bookings/:id
 // GetBookingByID godoc
 // @Summary      Get booking by ID
-// @Description  Menampilkan detail booking berdasarkan ID
+// @Description  Menampilkan detail booking milik user yang login, 404 untuk booking user lain
 // @Tags         Bookings
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id   path      int  true  "Booking ID"
 // @Success      200  {object}  models.Booking
-// @Failure      404  {object}  map[string]string
+// @Failure      404  {object}  apperror.Response
 // @Router       /api/bookings/{id} [get]
 func GetBookingByID(c *gin.Context) {
+	ctx := c.Request.Context()
 	id := c.Param("id")
 	var b models.Booking
 
 	query := `
-		SELECT id, court_id, user_id, customer_name, booking_date, start_time, end_time, total_price
-		FROM bookings WHERE id = $1
+		SELECT b.id, b.court_id, COALESCE(b.user_id, 0), b.customer_name, b.start_at, b.end_at, c.timezone, b.total_price, b.checked_in_at
+		FROM bookings b JOIN courts c ON c.id = b.court_id
+		WHERE b.id = $1 AND b.user_id = $2 AND b.deleted_at IS NULL
 	`
-	err := config.DB.QueryRow(query, id).Scan(
-		&b.ID, &b.CourtID, &b.UserID, &b.CustomerName, &b.BookingDate, &b.StartTime, &b.EndTime, &b.TotalPrice,
+	err := config.DB.QueryRowContext(ctx, query, id, c.GetInt("user_id")).Scan(
+		&b.ID, &b.CourtID, &b.UserID, &b.CustomerName, &b.StartAt, &b.EndAt, &b.Timezone, &b.TotalPrice, &b.CheckedInAt,
 	)
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		c.Error(apperror.FromDB(err, "Booking not found"))
 		return
 	}
 
@@ -74,41 +89,62 @@ func GetBookingByID(c *gin.Context) {
 // POST /bookings
 // CreateBooking godoc
 // @Summary      Create new booking
-// @Description  Membuat data booking baru
+// @Description  Membuat data booking baru. Total harga dihitung dari harga lapangan per jam.
+// @Description  Email user harus sudah diverifikasi (EMAIL_NOT_VERIFIED).
 // @Tags         Bookings
 // @Accept       json
 // @Produce      json
-// @Param        booking  body  models.Booking  true  "Booking Data"
+// @Security     BearerAuth
+// @Param        booking  body  dto.BookingRequest  true  "Booking Data"
+// @Param        Idempotency-Key  header  string  false  "Key unik per booking, request ulang dengan key yang sama mendapat response pertama"
 // @Success      201  {object}  models.Booking
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Failure      413  {object}  apperror.Response
+// @Failure      422  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /api/bookings [post]
 func CreateBooking(c *gin.Context) {
-	var newBooking models.Booking
-	if err := c.ShouldBindJSON(&newBooking); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	ctx := c.Request.Context()
+	var req dto.BookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
 		return
 	}
 
+	newBooking, err := prepareBooking(ctx, req, 0)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	newBooking.UserID = c.GetInt("user_id")
+
+	// booking_date, start_time dan end_time tetap diisi untuk kompatibilitas
 	query := `
-		INSERT INTO bookings (court_id, user_id, customer_name, booking_date, start_time, end_time, total_price)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id
+		INSERT INTO bookings (court_id, user_id, customer_name, booking_date, start_time, end_time, start_at, end_at, total_price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, to_jsonb(bookings)
 	`
-	err := config.DB.QueryRow(query,
+	var after []byte
+	err = config.DB.QueryRowContext(ctx, query,
 		newBooking.CourtID,
 		newBooking.UserID,
 		newBooking.CustomerName,
-		newBooking.BookingDate,
-		newBooking.StartTime,
-		newBooking.EndTime,
+		req.BookingDate,
+		req.StartTime,
+		req.EndTime,
+		newBooking.StartAt,
+		newBooking.EndAt,
 		newBooking.TotalPrice,
-	).Scan(&newBooking.ID)
+	).Scan(&newBooking.ID, &after)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.FromDB(err, "Booking not found"))
 		return
 	}
+	audit.Record(c, audit.ActionCreate, audit.EntityBooking, strconv.Itoa(newBooking.ID), nil, after)
+	metrics.BookingCreated()
 
 	c.JSON(http.StatusCreated, newBooking)
 }
@@ -116,82 +152,317 @@ func CreateBooking(c *gin.Context) {
 // PUT /bookings/:id
 // UpdateBooking godoc
 // @Summary      Update booking
-// @Description  Memperbarui data booking berdasarkan ID
+// @Description  Memperbarui booking milik user yang login, 404 untuk booking user lain
 // @Tags         Bookings
 // @Accept       json
 // @Produce      json
-// @Param        id      path      int          true  "Booking ID"
-// @Param        booking body      models.Booking true  "Booking Data"
+// @Security     BearerAuth
+// @Param        id      path      int                 true  "Booking ID"
+// @Param        booking body      dto.BookingRequest  true  "Booking Data"
 // @Success      200     {object}  map[string]string
-// @Failure      400     {object}  map[string]string
-// @Failure      404     {object}  map[string]string
-// @Failure      500     {object}  map[string]string
+// @Failure      400     {object}  apperror.Response
+// @Failure      403     {object}  apperror.Response
+// @Failure      404     {object}  apperror.Response
+// @Failure      409     {object}  apperror.Response
+// @Failure      500     {object}  apperror.Response
 // @Router       /api/bookings/{id} [put]
 func UpdateBooking(c *gin.Context) {
-	id := c.Param("id")
-	var updated models.Booking
+	ctx := c.Request.Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.NotFound("Booking not found"))
+		return
+	}
 
-	if err := c.ShouldBindJSON(&updated); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.BookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	// Cek kepemilikan sebelum validasi jadwal supaya booking user lain selalu 404
+	userID := c.GetInt("user_id")
+	var owned bool
+	err = config.DB.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM bookings WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)", id, userID,
+	).Scan(&owned)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if !owned {
+		c.Error(apperror.NotFound("Booking not found"))
+		return
+	}
+
+	updated, err := prepareBooking(ctx, req, id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	query := `
-		UPDATE bookings 
-		SET court_id=$1, user_id=$2, customer_name=$3, booking_date=$4, start_time=$5, end_time=$6, total_price=$7
-		WHERE id=$8
+		UPDATE bookings b
+		SET court_id=$1, customer_name=$2, booking_date=$3, start_time=$4, end_time=$5, start_at=$6, end_at=$7, total_price=$8
+		FROM bookings old
+		WHERE b.id=$9 AND b.user_id=$10 AND b.deleted_at IS NULL AND old.id = b.id
+		RETURNING to_jsonb(old), to_jsonb(b)
 	`
-	res, err := config.DB.Exec(query,
+	var before, after []byte
+	err = config.DB.QueryRowContext(ctx, query,
 		updated.CourtID,
-		updated.UserID,
 		updated.CustomerName,
-		updated.BookingDate,
-		updated.StartTime,
-		updated.EndTime,
+		req.BookingDate,
+		req.StartTime,
+		req.EndTime,
+		updated.StartAt,
+		updated.EndAt,
 		updated.TotalPrice,
 		id,
-	)
+		userID,
+	).Scan(&before, &after)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.FromDB(err, "Booking not found"))
 		return
 	}
+	audit.Record(c, audit.ActionUpdate, audit.EntityBooking, strconv.Itoa(id), before, after)
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
-		return
+	c.JSON(http.StatusOK, gin.H{"message": "Booking updated successfully"})
+}
+
+// prepareBooking memeriksa lapangan dan jadwal, lalu menghitung waktu dan total harga.
+// Tanggal dan jam di request dibaca dalam timezone lapangan.
+// excludeID diisi ID booking yang sedang diupdate supaya tidak bentrok dengan dirinya sendiri.
+func prepareBooking(ctx context.Context, req dto.BookingRequest, excludeID int) (models.Booking, error) {
+	b := models.Booking{
+		CourtID:      req.CourtID,
+		CustomerName: req.CustomerName,
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Booking updated successfully"})
+	var pricePerHour int
+	var isAvailable bool
+	var opening, closing sql.NullString
+	err := config.DB.QueryRowContext(ctx, `
+		SELECT c.price_per_hour, c.is_available, c.timezone, v.opening_time, v.closing_time
+		FROM courts c LEFT JOIN venues v ON v.id = c.venue_id
+		WHERE c.id = $1 AND c.deleted_at IS NULL
+	`, req.CourtID).Scan(&pricePerHour, &isAvailable, &b.Timezone, &opening, &closing)
+	if err == sql.ErrNoRows {
+		return b, apperror.Validation(apperror.FieldError{Field: "court_id", Message: "court does not exist"})
+	}
+	if err != nil {
+		return b, apperror.Internal(err)
+	}
+	if !isAvailable {
+		return b, apperror.Validation(apperror.FieldError{Field: "court_id", Message: "court is not available for booking"})
+	}
+
+	loc, err := venuetime.Load(b.Timezone)
+	if err != nil {
+		return b, apperror.Internal(err)
+	}
+	// Format tanggal dan jam sudah divalidasi oleh binding
+	b.StartAt, b.EndAt, err = venuetime.Range(req.BookingDate, req.StartTime, req.EndTime, loc)
+	if err != nil {
+		return b, apperror.Validation(apperror.FieldError{Field: "booking_date", Message: "is not a valid date"})
+	}
+	if !b.StartAt.After(time.Now()) {
+		return b, apperror.Validation(apperror.FieldError{Field: "start_time", Message: "must be in the future"})
+	}
+	if opening.Valid && closing.Valid && !venuetime.WithinHours(b.StartAt, b.EndAt, opening.String, closing.String, loc) {
+		return b, apperror.Validation(apperror.FieldError{
+			Field:   "start_time",
+			Message: "booking must be within venue opening hours " + opening.String + "-" + closing.String,
+		})
+	}
+
+	var overlapping int
+	err = config.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM bookings
+		WHERE court_id = $1 AND start_at < $3 AND end_at > $2 AND id <> $4 AND deleted_at IS NULL
+	`, req.CourtID, b.StartAt, b.EndAt, excludeID).Scan(&overlapping)
+	if err != nil {
+		return b, apperror.Internal(err)
+	}
+	if overlapping > 0 {
+		return b, apperror.BookingConflict("Court is already booked for the requested time")
+	}
+
+	b.TotalPrice = pricePerHour * int(b.EndAt.Sub(b.StartAt).Minutes()) / 60
+
+	return b, nil
 }
 
 // DELETE /bookings/:id
 // DeleteBooking godoc
 // @Summary      Delete booking
-// @Description  Menghapus data booking berdasarkan ID
+// @Description  Menghapus (soft delete) booking milik user yang login, 404 untuk booking user lain.
+// @Description  Jadwalnya bisa dibooking lagi.
 // @Tags         Bookings
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id   path      int  true  "Booking ID"
 // @Success      200  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /api/bookings/{id} [delete]
 func DeleteBooking(c *gin.Context) {
+	deleteBooking(c, c.GetInt("user_id"))
+}
+
+// deleteBooking melakukan soft delete booking c.Param("id"). ownerID membatasi ke
+// booking milik user tersebut; 0 berarti tanpa batas (venue sudah dicek pemanggil).
+func deleteBooking(c *gin.Context, ownerID int) {
+	ctx := c.Request.Context()
 	id := c.Param("id")
 
-	query := `DELETE FROM bookings WHERE id = $1`
-	res, err := config.DB.Exec(query, id)
+	query := `
+		UPDATE bookings b SET deleted_at = NOW() FROM bookings old
+		WHERE b.id = $1 AND ($2 = 0 OR b.user_id = $2) AND b.deleted_at IS NULL AND old.id = b.id
+		RETURNING to_jsonb(old)
+	`
+	var before []byte
+	err := config.DB.QueryRowContext(ctx, query, id, ownerID).Scan(&before)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.FromDB(err, "Booking not found"))
 		return
 	}
+	audit.Record(c, audit.ActionDelete, audit.EntityBooking, id, before, nil)
+	metrics.BookingsCancelled(1)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Booking deleted successfully"})
+}
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+// GET /admin/bookings
+// GetManagedBookings godoc
+// @Summary      Get bookings of managed venues
+// @Description  Menampilkan booking di venue yang ditugaskan (permission bookings:read_all). Admin melihat semua booking.
+// @Tags         Bookings
+// @Produce      json
+// @Security     BearerAuth
+// @Param        venue_id  query     int  false  "Filter venue"
+// @Success      200       {array}   models.Booking
+// @Failure      401       {object}  apperror.Response
+// @Failure      403       {object}  apperror.Response
+// @Router       /api/admin/bookings [get]
+func GetManagedBookings(c *gin.Context) {
+	ctx := c.Request.Context()
+	venueID, _ := strconv.Atoi(c.Query("venue_id"))
+
+	all, err := canAccessAllVenues(c)
+	if err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Booking deleted successfully"})
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT b.id, b.court_id, COALESCE(b.user_id, 0), b.customer_name, b.start_at, b.end_at, c.timezone, b.total_price, b.checked_in_at
+		FROM bookings b JOIN courts c ON c.id = b.court_id
+		WHERE b.deleted_at IS NULL
+		  AND ($1 OR c.venue_id IN (SELECT venue_id FROM venue_managers WHERE user_id = $2)
+		          OR c.venue_id IN (SELECT venue_id FROM api_key_venues WHERE api_key_id = $4))
+		  AND ($3 = 0 OR c.venue_id = $3)
+		ORDER BY b.start_at DESC
+	`, all, c.GetInt("user_id"), venueID, apiKeyID(c))
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer rows.Close()
+
+	bookings := []models.Booking{}
+	for rows.Next() {
+		var b models.Booking
+		if err := rows.Scan(&b.ID, &b.CourtID, &b.UserID, &b.CustomerName, &b.StartAt, &b.EndAt, &b.Timezone, &b.TotalPrice, &b.CheckedInAt); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		bookings = append(bookings, b)
+	}
+
+	c.JSON(http.StatusOK, bookings)
+}
+
+// DELETE /admin/bookings/:id
+// DeleteManagedBooking godoc
+// @Summary      Delete booking in managed venue
+// @Description  Menghapus booking di venue yang ditugaskan (permission bookings:cancel)
+// @Tags         Bookings
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Booking ID"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/bookings/{id} [delete]
+func DeleteManagedBooking(c *gin.Context) {
+	venueID, err := bookingVenueID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := authorizeVenue(c, venueID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	deleteBooking(c, 0)
+}
+
+// POST /admin/bookings/:id/restore
+// RestoreBooking godoc
+// @Summary      Restore booking
+// @Description  Mengembalikan booking yang dihapus di venue yang ditugaskan (permission bookings:cancel).
+// @Description  Gagal dengan 409 jika jadwalnya sudah dipakai booking lain.
+// @Tags         Bookings
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Booking ID"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Router       /api/admin/bookings/{id}/restore [post]
+func RestoreBooking(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	var venueID sql.NullInt64
+	var courtDeleted bool
+	err := config.DB.QueryRowContext(ctx, `
+		SELECT c.venue_id, c.deleted_at IS NOT NULL
+		FROM bookings b JOIN courts c ON c.id = b.court_id
+		WHERE b.id = $1 AND b.deleted_at IS NOT NULL
+	`, id).Scan(&venueID, &courtDeleted)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Deleted booking not found"))
+		return
+	}
+	var venue *int
+	if venueID.Valid {
+		venue = optionalID(int(venueID.Int64))
+	}
+	if err := authorizeVenue(c, venue); err != nil {
+		c.Error(err)
+		return
+	}
+	if courtDeleted {
+		c.Error(apperror.Conflict("Restore the court before restoring its bookings"))
+		return
+	}
+
+	var after []byte
+	err = config.DB.QueryRowContext(ctx,
+		"UPDATE bookings SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING to_jsonb(bookings)", id,
+	).Scan(&after)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Deleted booking not found"))
+		return
+	}
+	audit.Record(c, audit.ActionRestore, audit.EntityBooking, id, nil, after)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Booking restored successfully"})
 }
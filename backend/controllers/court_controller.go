@@ -1,10 +1,19 @@
 package controllers
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/audit"
 	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/metrics"
 	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/HenryKristofani/GoFutsal/venuetime"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,9 +25,10 @@ import (
 // @Success      200  {array}  models.Court
 // @Router       /api/courts [get]
 func GetCourts(c *gin.Context) {
-	rows, err := config.DB.Query("SELECT id, name, location, price_per_hour, is_available FROM courts ORDER BY id")
+	ctx := c.Request.Context()
+	rows, err := config.DB.QueryContext(ctx, "SELECT id, venue_id, name, location, price_per_hour, is_available, timezone FROM courts WHERE deleted_at IS NULL ORDER BY id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.Internal(err))
 		return
 	}
 	defer rows.Close()
@@ -26,8 +36,8 @@ func GetCourts(c *gin.Context) {
 	var courts []models.Court
 	for rows.Next() {
 		var court models.Court
-		if err := rows.Scan(&court.ID, &court.Name, &court.Location, &court.PricePerHour, &court.IsAvailable); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := rows.Scan(&court.ID, &court.VenueID, &court.Name, &court.Location, &court.PricePerHour, &court.IsAvailable, &court.Timezone); err != nil {
+			c.Error(apperror.Internal(err))
 			return
 		}
 		courts = append(courts, court)
@@ -43,17 +53,18 @@ func GetCourts(c *gin.Context) {
 // @Produce      json
 // @Param        id   path      int  true  "Court ID"
 // @Success      200  {object}  models.Court
-// @Failure      404  {object}  map[string]string
+// @Failure      404  {object}  apperror.Response
 // @Router       /api/courts/{id} [get]
 func GetCourtByID(c *gin.Context) {
+	ctx := c.Request.Context()
 	id := c.Param("id")
 	var court models.Court
 
-	err := config.DB.QueryRow("SELECT id, name, location, price_per_hour, is_available FROM courts WHERE id = $1", id).
-		Scan(&court.ID, &court.Name, &court.Location, &court.PricePerHour, &court.IsAvailable)
+	err := config.DB.QueryRowContext(ctx, "SELECT id, venue_id, name, location, price_per_hour, is_available, timezone FROM courts WHERE id = $1 AND deleted_at IS NULL", id).
+		Scan(&court.ID, &court.VenueID, &court.Name, &court.Location, &court.PricePerHour, &court.IsAvailable, &court.Timezone)
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
+		c.Error(apperror.FromDB(err, "Court not found"))
 		return
 	}
 
@@ -62,92 +73,306 @@ func GetCourtByID(c *gin.Context) {
 
 // CreateCourt godoc
 // @Summary      Tambah lapangan baru
-// @Description  Menambahkan data lapangan futsal baru (Admin only)
+// @Description  Menambahkan data lapangan futsal baru (permission courts:write, hanya venue yang ditugaskan)
 // @Tags         Courts
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        court  body  models.Court  true  "Court Data"
+// @Param        court  body  dto.CourtRequest  true  "Court Data"
 // @Success      201  {object}  models.Court
-// @Failure      401  {object}  map[string]interface{}
-// @Failure      403  {object}  map[string]interface{}
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
 // @Router       /api/admin/courts [post]
 func CreateCourt(c *gin.Context) {
-	var court models.Court
-	if err := c.ShouldBindJSON(&court); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	ctx := c.Request.Context()
+	var req dto.CourtRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
 		return
 	}
 
-	err := config.DB.QueryRow(
-		"INSERT INTO courts (name, location, price_per_hour, is_available) VALUES ($1, $2, $3, $4) RETURNING id",
-		court.Name, court.Location, court.PricePerHour, court.IsAvailable,
-	).Scan(&court.ID)
+	court := models.Court{
+		VenueID:      optionalID(req.VenueID),
+		Name:         req.Name,
+		Location:     req.Location,
+		PricePerHour: req.PricePerHour,
+		IsAvailable:  req.IsAvailable,
+	}
+
+	if err := authorizeVenue(c, court.VenueID); err != nil {
+		c.Error(err)
+		return
+	}
 
+	timezone, err := courtTimezone(ctx, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
+	court.Timezone = timezone
+
+	var after []byte
+	err = config.DB.QueryRowContext(ctx,
+		"INSERT INTO courts (venue_id, name, location, price_per_hour, is_available, timezone) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, to_jsonb(courts)",
+		court.VenueID, court.Name, court.Location, court.PricePerHour, court.IsAvailable, court.Timezone,
+	).Scan(&court.ID, &after)
+
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Court not found"))
+		return
+	}
+	audit.Record(c, audit.ActionCreate, audit.EntityCourt, strconv.Itoa(court.ID), nil, after)
 
 	c.JSON(http.StatusCreated, court)
 }
 
 // UpdateCourt godoc
 // @Summary      Update court
-// @Description  Memperbarui data lapangan futsal berdasarkan ID (Admin only)
+// @Description  Memperbarui data lapangan futsal berdasarkan ID (permission courts:write, hanya venue yang ditugaskan)
 // @Tags         Courts
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id     path      int          true  "Court ID"
-// @Param        court  body      models.Court true  "Court Data"
+// @Param        court  body      dto.CourtRequest true  "Court Data"
 // @Success      200    {object}  map[string]string
-// @Failure      400    {object}  map[string]string
-// @Failure      401    {object}  map[string]interface{}
-// @Failure      403    {object}  map[string]interface{}
-// @Failure      500    {object}  map[string]string
+// @Failure      400    {object}  apperror.Response
+// @Failure      401    {object}  apperror.Response
+// @Failure      403    {object}  apperror.Response
+// @Failure      404    {object}  apperror.Response
+// @Failure      500    {object}  apperror.Response
 // @Router       /api/admin/courts/{id} [put]
 func UpdateCourt(c *gin.Context) {
+	ctx := c.Request.Context()
 	id := c.Param("id")
-	var court models.Court
+	var req dto.CourtRequest
 
-	if err := c.ShouldBindJSON(&court); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
 		return
 	}
 
-	_, err := config.DB.Exec(
-		"UPDATE courts SET name=$1, location=$2, price_per_hour=$3, is_available=$4 WHERE id=$5",
-		court.Name, court.Location, court.PricePerHour, court.IsAvailable, id,
-	)
+	// User harus mengelola venue lama dan venue baru (jika lapangan dipindah)
+	currentVenueID, err := courtVenueID(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := authorizeVenue(c, currentVenueID); err != nil {
+		c.Error(err)
+		return
+	}
+	venueID := optionalID(req.VenueID)
+	if err := authorizeVenue(c, venueID); err != nil {
+		c.Error(err)
+		return
+	}
 
+	timezone, err := courtTimezone(ctx, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	var before, after []byte
+	err = config.DB.QueryRowContext(ctx, `
+		UPDATE courts c SET venue_id=$1, name=$2, location=$3, price_per_hour=$4, is_available=$5, timezone=$6
+		FROM courts old
+		WHERE c.id=$7 AND c.deleted_at IS NULL AND old.id = c.id
+		RETURNING to_jsonb(old), to_jsonb(c)
+	`, venueID, req.Name, req.Location, req.PricePerHour, req.IsAvailable, timezone, id).Scan(&before, &after)
+
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Court not found"))
+		return
+	}
+	audit.Record(c, audit.ActionUpdate, audit.EntityCourt, id, before, after)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Court updated successfully"})
 }
 
 // DeleteCourt godoc
 // @Summary      Delete court
-// @Description  Menghapus data lapangan futsal berdasarkan ID (Admin only)
+// @Description  Menghapus (soft delete) lapangan berdasarkan ID (permission courts:write, hanya venue yang ditugaskan).
+// @Description  Lapangan yang masih punya booking mendatang tidak bisa dihapus, kecuali dengan force=true
+// @Description  yang sekaligus membatalkan semua booking mendatang di lapangan tersebut.
 // @Tags         Courts
 // @Produce      json
 // @Security     BearerAuth
-// @Param        id   path      int  true  "Court ID"
-// @Success      200  {object}  map[string]string
-// @Failure      401  {object}  map[string]interface{}
-// @Failure      403  {object}  map[string]interface{}
-// @Failure      500  {object}  map[string]string
+// @Param        id     path      int   true   "Court ID"
+// @Param        force  query     bool  false  "Batalkan semua booking mendatang"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /api/admin/courts/{id} [delete]
 func DeleteCourt(c *gin.Context) {
+	ctx := c.Request.Context()
 	id := c.Param("id")
-	_, err := config.DB.Exec("DELETE FROM courts WHERE id = $1", id)
+	force := c.Query("force") == "true"
+
+	venueID, err := courtVenueID(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	if err := authorizeVenue(c, venueID); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Court deleted successfully"})
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	// Menandai lapangan terhapus lebih dulu supaya baris lapangan terkunci
+	// sampai booking mendatang selesai dicek
+	var before []byte
+	err = tx.QueryRowContext(ctx, `
+		UPDATE courts c SET deleted_at = NOW() FROM courts old
+		WHERE c.id = $1 AND c.deleted_at IS NULL AND old.id = c.id
+		RETURNING to_jsonb(old)
+	`, id).Scan(&before)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Court not found"))
+		return
+	}
+
+	var upcoming int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM bookings WHERE court_id = $1 AND deleted_at IS NULL AND end_at > NOW()", id,
+	).Scan(&upcoming)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if upcoming > 0 && !force {
+		c.Error(apperror.Conflict(fmt.Sprintf("Court has %d upcoming bookings, use force=true to cancel them", upcoming)))
+		return
+	}
+
+	type cancelled struct {
+		id     int
+		before []byte
+	}
+	var bookings []cancelled
+	if upcoming > 0 {
+		rows, err := tx.QueryContext(ctx, `
+			UPDATE bookings b SET deleted_at = NOW() FROM bookings old
+			WHERE b.court_id = $1 AND b.deleted_at IS NULL AND b.end_at > NOW() AND old.id = b.id
+			RETURNING b.id, to_jsonb(old)
+		`, id)
+		if err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		for rows.Next() {
+			var b cancelled
+			if err := rows.Scan(&b.id, &b.before); err != nil {
+				rows.Close()
+				c.Error(apperror.Internal(err))
+				return
+			}
+			bookings = append(bookings, b)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	for _, b := range bookings {
+		audit.Record(c, audit.ActionDelete, audit.EntityBooking, strconv.Itoa(b.id), b.before, nil)
+	}
+	audit.Record(c, audit.ActionDelete, audit.EntityCourt, id, before, nil)
+	metrics.BookingsCancelled(len(bookings))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Court deleted successfully", "cancelled_bookings": len(bookings)})
+}
+
+// RestoreCourt godoc
+// @Summary      Restore court
+// @Description  Mengembalikan lapangan yang dihapus (permission courts:write, hanya venue yang ditugaskan).
+// @Description  Booking yang ikut dibatalkan saat lapangan dihapus tidak ikut dikembalikan.
+// @Tags         Courts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Court ID"
+// @Success      200  {object}  models.Court
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/courts/{id}/restore [post]
+func RestoreCourt(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	var venueID sql.NullInt64
+	err := config.DB.QueryRowContext(ctx, "SELECT venue_id FROM courts WHERE id = $1 AND deleted_at IS NOT NULL", id).Scan(&venueID)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Deleted court not found"))
+		return
+	}
+	var venue *int
+	if venueID.Valid {
+		venue = optionalID(int(venueID.Int64))
+	}
+	if err := authorizeVenue(c, venue); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var court models.Court
+	var after []byte
+	err = config.DB.QueryRowContext(ctx, `
+		UPDATE courts SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING id, venue_id, name, location, price_per_hour, is_available, timezone, to_jsonb(courts)
+	`, id).Scan(&court.ID, &court.VenueID, &court.Name, &court.Location, &court.PricePerHour, &court.IsAvailable, &court.Timezone, &after)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Deleted court not found"))
+		return
+	}
+	audit.Record(c, audit.ActionRestore, audit.EntityCourt, id, nil, after)
+
+	c.JSON(http.StatusOK, court)
+}
+
+// courtTimezone menentukan timezone lapangan: ikut venue jika venue_id diisi,
+// lalu timezone dari request, lalu timezone default
+func courtTimezone(ctx context.Context, req dto.CourtRequest) (string, error) {
+	if req.VenueID > 0 {
+		var timezone string
+		err := config.DB.QueryRowContext(ctx, "SELECT timezone FROM venues WHERE id = $1", req.VenueID).Scan(&timezone)
+		if err == sql.ErrNoRows {
+			return "", apperror.Validation(apperror.FieldError{Field: "venue_id", Message: "venue does not exist"})
+		}
+		if err != nil {
+			return "", apperror.Internal(err)
+		}
+		return timezone, nil
+	}
+
+	if req.Timezone != "" {
+		return req.Timezone, nil
+	}
+	return venuetime.Default().String(), nil
+}
+
+// optionalID mengubah ID 0 (tidak diisi) menjadi nil untuk kolom nullable
+func optionalID(id int) *int {
+	if id <= 0 {
+		return nil
+	}
+	return &id
 }
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/purge"
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout adalah batas waktu seluruh pengecekan readiness
+const readinessTimeout = 2 * time.Second
+
+// Status pengecekan yang ditampilkan di response, tanpa detail error
+const (
+	checkOK   = "ok"
+	checkFail = "fail"
+)
+
+// readinessChecks adalah dependency yang harus sehat sebelum instance menerima traffic.
+// Detail error hanya di-log, tidak dikirim ke client.
+var readinessChecks = []struct {
+	name  string
+	check func(ctx context.Context) error
+}{
+	{"database", func(ctx context.Context) error { return config.DB.PingContext(ctx) }},
+	{"migrations", config.MigrationsUpToDate},
+	{"workers", func(context.Context) error { return purge.Health() }},
+}
+
+// HealthResponse adalah status service. Versi dan commit build tidak ditampilkan
+// di endpoint publik, hanya dicatat di log saat server start.
+type HealthResponse struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// ReadinessResponse adalah hasil setiap pengecekan readiness
+type ReadinessResponse struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]string `json:"checks" example:"database:ok,migrations:ok,workers:ok"`
+}
+
+// Health godoc
+// @Summary      Health
+// @Description  Status service tanpa detail internal (versi build hanya ada di log server)
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  HealthResponse
+// @Router       /health [get]
+func Health(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Livez godoc
+// @Summary      Liveness probe
+// @Description  Selalu 200 selama proses bisa melayani request, tidak mengecek dependency
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  HealthResponse
+// @Router       /livez [get]
+func Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz godoc
+// @Summary      Readiness probe
+// @Description  Mengecek koneksi database, migrasi yang sudah dijalankan dan background worker.
+// @Description  503 jika salah satu gagal; detail error hanya dicatat di log server.
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  ReadinessResponse
+// @Failure      503  {object}  ReadinessResponse
+// @Router       /readyz [get]
+func Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	res := ReadinessResponse{Status: "ok", Checks: map[string]string{}}
+	status := http.StatusOK
+	for _, rc := range readinessChecks {
+		if err := rc.check(ctx); err != nil {
+			slog.WarnContext(ctx, "readiness check failed", "check", rc.name, "error", err)
+			res.Checks[rc.name] = checkFail
+			res.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		res.Checks[rc.name] = checkOK
+	}
+
+	c.JSON(status, res)
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public key untuk memverifikasi token GoFutsal (pilih kunci berdasarkan kid di header token).
+// @Description  Access token memiliki aud "gofutsal-api" dan header typ "at+jwt"; token dengan aud atau typ lain
+// @Description  (refresh, verifikasi email, challenge 2FA) harus ditolak.
+// @Tags         Authentication
+// @Produce      json
+// @Success      200  {object}  auth.JWKSet
+// @Failure      500  {object}  apperror.Response
+// @Router       /.well-known/jwks.json [get]
+func JWKS(c *gin.Context) {
+	set, err := auth.JWKS()
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	// Service lain boleh cache sebentar, kunci baru tetap cepat terlihat saat rotasi
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, set)
+}
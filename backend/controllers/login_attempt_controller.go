@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/audit"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/loginguard"
+	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetLoginAttempts godoc
+// @Summary      Get login attempts
+// @Description  Menampilkan audit percobaan login terbaru (permission users:manage)
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username  query  string  false  "Filter username"
+// @Param        ip        query  string  false  "Filter IP address"
+// @Param        success   query  bool    false  "Filter berhasil/gagal"
+// @Param        limit     query  int     false  "Jumlah data (default 100, maksimal 500)"
+// @Success      200  {array}   models.LoginAttempt
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /api/admin/login-attempts [get]
+func GetLoginAttempts(c *gin.Context) {
+	ctx := c.Request.Context()
+	limit := 100
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 500 {
+			c.Error(apperror.Validation(apperror.FieldError{Field: "limit", Message: "limit must be between 1 and 500"}))
+			return
+		}
+		limit = n
+	}
+
+	var success *bool
+	if v := c.Query("success"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			c.Error(apperror.Validation(apperror.FieldError{Field: "success", Message: "success must be true or false"}))
+			return
+		}
+		success = &b
+	}
+
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT id, username, user_id, ip_address, user_agent, success, reason, created_at
+		FROM login_attempts
+		WHERE ($1 = '' OR LOWER(username) = LOWER($1))
+		  AND ($2 = '' OR ip_address = $2)
+		  AND ($3::BOOLEAN IS NULL OR success = $3)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`, c.Query("username"), c.Query("ip"), success, limit)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer rows.Close()
+
+	attempts := []models.LoginAttempt{}
+	for rows.Next() {
+		var a models.LoginAttempt
+		if err := rows.Scan(&a.ID, &a.Username, &a.UserID, &a.IPAddress, &a.UserAgent, &a.Success, &a.Reason, &a.CreatedAt); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		attempts = append(attempts, a)
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
+
+// UnlockUser godoc
+// @Summary      Unlock user login
+// @Description  Menghapus jeda dan kunci login akun setelah terlalu banyak gagal login (permission users:manage)
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "User ID"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/users/{id}/lockout [delete]
+func UnlockUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	var username string
+	err := config.DB.QueryRowContext(ctx, "SELECT username FROM users WHERE id = $1", c.Param("id")).Scan(&username)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "User not found"))
+		return
+	}
+
+	if err := loginguard.Unlock(c.Request.Context(), username); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	audit.Record(c, audit.ActionUnlock, audit.EntityUser, c.Param("id"), nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User login unlocked successfully"})
+}
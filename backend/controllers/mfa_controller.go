@@ -0,0 +1,432 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/loginguard"
+	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mfaIssuer adalah nama yang tampil di aplikasi authenticator
+const mfaIssuer = "GoFutsal"
+
+// recoveryCodeCount adalah jumlah kode pemulihan yang dibuat setiap kali
+const recoveryCodeCount = 10
+
+// MFAEnrollResponse berisi secret dan URI untuk QR code aplikasi authenticator
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/GoFutsal:johndoe?secret=JBSWY3DPEHPK3PXP&issuer=GoFutsal"`
+}
+
+// MFARecoveryCodesResponse berisi kode pemulihan, hanya ditampilkan sekali
+type MFARecoveryCodesResponse struct {
+	Message       string   `json:"message" example:"Two-factor authentication enabled"`
+	RecoveryCodes []string `json:"recovery_codes" example:"abcde-fghij,klmno-pqrst"`
+}
+
+// LoginMFA godoc
+// @Summary      Login step 2 (2FA)
+// @Description  Menukar mfa_token dari /api/auth/login dan kode authenticator (atau kode pemulihan)
+// @Description  dengan access token dan refresh token
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.MFALoginRequest  true  "MFA token dan kode"
+// @Success      200  {object}  LoginResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Router       /api/auth/login/mfa [post]
+func LoginMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	claims, err := auth.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid or expired MFA token"))
+		return
+	}
+
+	var user models.User
+	var secret sql.NullString
+	var lastStep int64
+	err = config.DB.QueryRowContext(ctx, `
+		SELECT id, username, email, role, email_verified_at IS NOT NULL, totp_secret, totp_last_step
+		FROM users WHERE id = $1 AND totp_enabled_at IS NOT NULL AND deleted_at IS NULL
+	`, claims.UserID).Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.EmailVerified, &secret, &lastStep)
+	if err == sql.ErrNoRows {
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid or expired MFA token"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	if !loginAllowed(c, user.Username) {
+		return
+	}
+
+	ok, err := verifyMFACode(ctx, user.ID, secret.String, lastStep, req.Code)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if !ok {
+		loginFailed(c, user.Username, &user.ID, models.LoginInvalidMFA)
+		c.Error(apperror.Unauthorized("Invalid authentication code"))
+		return
+	}
+
+	if err := loginguard.RecordSuccess(c.Request.Context(), user.Username); err != nil {
+		slog.ErrorContext(c.Request.Context(), "login guard failed", "error", err)
+	}
+	recordLoginAttempt(c, user.Username, &user.ID, true, models.LoginSuccess)
+
+	issueLoginTokens(c, user)
+}
+
+// EnrollMFA godoc
+// @Summary      Start 2FA enrollment
+// @Description  Membuat secret TOTP baru. 2FA baru aktif setelah kode pertama diverifikasi di /api/profile/2fa/verify.
+// @Tags         Two-Factor Authentication
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  MFAEnrollResponse
+// @Failure      401  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Router       /api/profile/2fa/enroll [post]
+func EnrollMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	var username string
+	err = config.DB.QueryRowContext(ctx, `
+		UPDATE users SET totp_secret = $1, totp_last_step = 0
+		WHERE id = $2 AND totp_enabled_at IS NULL
+		RETURNING username
+	`, secret, c.GetInt("user_id")).Scan(&username)
+	if err == sql.ErrNoRows {
+		c.Error(apperror.Conflict("Two-factor authentication is already enabled"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(secret, username, mfaIssuer),
+	})
+}
+
+// VerifyMFA godoc
+// @Summary      Verify 2FA enrollment
+// @Description  Mengaktifkan 2FA dengan kode pertama dari aplikasi authenticator dan mengembalikan kode pemulihan
+// @Tags         Two-Factor Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body  dto.MFACodeRequest  true  "Kode authenticator"
+// @Success      200  {object}  MFARecoveryCodesResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Router       /api/profile/2fa/verify [post]
+func VerifyMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	var secret sql.NullString
+	var enabled bool
+	err := config.DB.QueryRowContext(ctx,
+		"SELECT totp_secret, totp_enabled_at IS NOT NULL FROM users WHERE id = $1", userID,
+	).Scan(&secret, &enabled)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "User not found"))
+		return
+	}
+	if enabled {
+		c.Error(apperror.Conflict("Two-factor authentication is already enabled"))
+		return
+	}
+	if !secret.Valid {
+		c.Error(apperror.BadRequest("Start enrollment at /api/profile/2fa/enroll first"))
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(secret.String, req.Code, time.Now(), 0)
+	if !ok {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "code", Message: "code is invalid or expired"}))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE users SET totp_enabled_at = NOW(), totp_last_step = $1 WHERE id = $2 AND totp_enabled_at IS NULL AND totp_secret = $3",
+		step, userID, secret.String,
+	)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		c.Error(apperror.Conflict("Two-factor authentication enrollment changed, please try again"))
+		return
+	}
+	codes, err := replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, MFARecoveryCodesResponse{
+		Message:       "Two-factor authentication enabled",
+		RecoveryCodes: codes,
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate 2FA recovery codes
+// @Description  Membuat kode pemulihan baru, kode lama tidak berlaku lagi
+// @Tags         Two-Factor Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body  dto.MFACodeRequest  true  "Kode authenticator"
+// @Success      200  {object}  MFARecoveryCodesResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Router       /api/profile/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	secret, lastStep, err := enabledMFA(ctx, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	ok, err := verifyMFACode(ctx, userID, secret, lastStep, req.Code)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if !ok {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "code", Message: "code is invalid or expired"}))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, MFARecoveryCodesResponse{
+		Message:       "Recovery codes regenerated",
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableMFA godoc
+// @Summary      Disable 2FA
+// @Description  Menonaktifkan 2FA dengan password dan kode authenticator (atau kode pemulihan).
+// @Description  Tidak bisa dilakukan jika role user mewajibkan 2FA.
+// @Tags         Two-Factor Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body  dto.DisableMFARequest  true  "Password dan kode"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /api/profile/2fa/disable [post]
+func DisableMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	var hashedPassword string
+	var required bool
+	err := config.DB.QueryRowContext(ctx, `
+		SELECT u.password, COALESCE(r.mfa_required, FALSE)
+		FROM users u LEFT JOIN roles r ON r.name = u.role
+		WHERE u.id = $1
+	`, userID).Scan(&hashedPassword, &required)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "User not found"))
+		return
+	}
+	if required {
+		c.Error(apperror.Forbidden("Two-factor authentication is required for your role"))
+		return
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.Password)) != nil {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "password", Message: "password is incorrect"}))
+		return
+	}
+
+	secret, lastStep, err := enabledMFA(ctx, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	ok, err := verifyMFACode(ctx, userID, secret, lastStep, req.Code)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if !ok {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "code", Message: "code is invalid or expired"}))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0 WHERE id = $1",
+		userID,
+	)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_codes WHERE user_id = $1", userID); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// enabledMFA mengambil secret TOTP user yang 2FA-nya sudah aktif
+func enabledMFA(ctx context.Context, userID int) (string, int64, error) {
+	var secret sql.NullString
+	var lastStep int64
+	err := config.DB.QueryRowContext(ctx,
+		"SELECT totp_secret, totp_last_step FROM users WHERE id = $1 AND totp_enabled_at IS NOT NULL", userID,
+	).Scan(&secret, &lastStep)
+	if err == sql.ErrNoRows {
+		return "", 0, apperror.BadRequest("Two-factor authentication is not enabled")
+	}
+	if err != nil {
+		return "", 0, apperror.Internal(err)
+	}
+	return secret.String, lastStep, nil
+}
+
+// verifyMFACode mengecek kode authenticator atau kode pemulihan dan menandainya terpakai.
+// Update dilakukan dengan kondisi supaya kode yang sama tidak bisa dipakai dua kali secara paralel.
+func verifyMFACode(ctx context.Context, userID int, secret string, lastStep int64, code string) (bool, error) {
+	if step, ok := auth.ValidateTOTP(secret, code, time.Now(), lastStep); ok {
+		res, err := config.DB.ExecContext(ctx,
+			"UPDATE users SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1",
+			step, userID,
+		)
+		if err != nil {
+			return false, err
+		}
+		rows, _ := res.RowsAffected()
+		return rows == 1, nil
+	}
+
+	res, err := config.DB.ExecContext(ctx,
+		"UPDATE user_recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL",
+		userID, auth.HashToken(auth.NormalizeRecoveryCode(code)),
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := res.RowsAffected()
+	return rows == 1, nil
+}
+
+// replaceRecoveryCodes menghapus kode pemulihan lama dan menyimpan hash kode baru
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)",
+			userID, auth.HashToken(auth.NormalizeRecoveryCode(code)),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
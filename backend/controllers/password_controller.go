@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/audit"
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/mailer"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordResetTTL adalah masa berlaku link reset password
+const passwordResetTTL = time.Hour
+
+// ForgotPassword godoc
+// @Summary      Request password reset
+// @Description  Mengirim link reset password ke email yang sudah diverifikasi. Response selalu sama,
+// @Description  baik email terdaftar maupun tidak.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.ForgotPasswordRequest  true  "Email akun"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Router       /api/auth/forgot-password [post]
+func ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	// Diproses di background dan error hanya di-log, supaya isi maupun waktu
+	// response tidak membocorkan apakah akun dengan email tersebut ada
+	go func(ctx context.Context, email string) {
+		if err := sendPasswordReset(ctx, email); err != nil {
+			slog.ErrorContext(ctx, "send password reset failed", "error", err)
+		}
+	}(context.WithoutCancel(c.Request.Context()), req.Email)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If an account with that email exists, a password reset link has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Mengganti password dengan token dari email. Token hanya bisa dipakai sekali
+// @Description  dan semua session user dicabut (logout dari semua perangkat).
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.ResetPasswordRequest  true  "Token dan password baru"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Router       /api/auth/reset-password [post]
+func ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	// Tandai token terpakai di query yang sama supaya request paralel tidak bisa memakai token dua kali
+	var userID int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`, auth.HashToken(req.Token)).Scan(&userID)
+	if err == sql.ErrNoRows {
+		c.Error(apperror.BadRequest("Invalid or expired reset token"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	if err := setPassword(ctx, tx, userID, req.NewPassword, ""); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	audit.Record(c, audit.ActionPasswordReset, audit.EntityUser, strconv.Itoa(userID), nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
+
+// sendPasswordReset membuat token reset untuk user dengan email tersebut (jika ada)
+// dan mengirimkan link-nya lewat mailer. Email yang belum diverifikasi tidak dikirimi link,
+// supaya email yang baru diganti (mungkin oleh orang lain) tidak bisa dipakai mengambil alih akun.
+func sendPasswordReset(ctx context.Context, email string) error {
+	var userID int
+	err := config.DB.QueryRowContext(ctx, `
+		SELECT id, email FROM users
+		WHERE LOWER(email) = LOWER($1) AND email_verified_at IS NOT NULL AND deleted_at IS NULL
+	`, email).Scan(&userID, &email)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := auth.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Hanya link terakhir yang berlaku
+	if _, err := tx.ExecContext(ctx, "UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL", userID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		userID, auth.HashToken(token), time.Now().Add(passwordResetTTL),
+	)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	link := appURL("/reset-password?token=" + url.QueryEscape(token))
+	return mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Reset your GoFutsal password",
+		Body: fmt.Sprintf(
+			"We received a request to reset your password.\n\nOpen this link within %d minutes to choose a new password:\n%s\n\nIf you did not request this, you can ignore this email.",
+			int(passwordResetTTL.Minutes()), link,
+		),
+	})
+}
+
+// setPassword menyimpan hash password baru dan mencabut semua session user
+// kecuali keepSession (session yang sedang dipakai saat ganti password)
+func setPassword(ctx context.Context, tx *sql.Tx, userID int, password, keepSession string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET password = $1 WHERE id = $2", string(hashed), userID); err != nil {
+		return err
+	}
+	return auth.RevokeSessions(ctx, tx, userID, keepSession)
+}
+
+// appBaseURL adalah URL frontend (APP_BASE_URL) untuk link di email, diatur lewat SetAppBaseURL
+var appBaseURL = "http://localhost:3000"
+
+// SetAppBaseURL mengatur URL frontend yang dipakai di link email
+func SetAppBaseURL(base string) {
+	appBaseURL = strings.TrimRight(base, "/")
+}
+
+// appURL membuat URL frontend untuk link di email
+func appURL(path string) string {
+	return appBaseURL + path
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HenryKristofani/GoFutsal/config"
+)
+
+func TestSendPasswordResetSkipsUnverifiedEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = prev
+		db.Close()
+	})
+
+	// Email yang belum diverifikasi tidak cocok, jadi tidak ada token yang dibuat
+	mock.ExpectQuery(`WHERE LOWER\(email\) = LOWER\(\$1\) AND email_verified_at IS NOT NULL`).
+		WithArgs("Admin@Example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}))
+
+	if err := sendPasswordReset(context.Background(), "Admin@Example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
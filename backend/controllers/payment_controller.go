@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/audit"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/metrics"
+	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CheckInBooking godoc
+// @Summary      Check-in booking
+// @Description  Menandai pelanggan sudah datang untuk booking (permission bookings:checkin)
+// @Tags         Payments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Booking ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Router       /api/admin/bookings/{id}/checkin [post]
+func CheckInBooking(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	venueID, err := bookingVenueID(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := authorizeVenue(c, venueID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var before, after []byte
+	err = config.DB.QueryRowContext(ctx, `
+		UPDATE bookings b SET checked_in_at = NOW(), checked_in_by = $1, checked_in_by_api_key = $3
+		FROM bookings old
+		WHERE b.id = $2 AND b.checked_in_at IS NULL AND b.deleted_at IS NULL AND old.id = b.id
+		RETURNING to_jsonb(old), to_jsonb(b)
+	`, optionalID(c.GetInt("user_id")), id, apiKeyID(c)).Scan(&before, &after)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Booking bisa saja dihapus setelah pengecekan venue di atas
+		if _, err := bookingVenueID(ctx, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Error(apperror.Conflict("Booking is already checked in"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Booking not found"))
+		return
+	}
+	audit.Record(c, audit.ActionCheckIn, audit.EntityBooking, id, before, after)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Booking checked in successfully"})
+}
+
+// CreatePayment godoc
+// @Summary      Record payment
+// @Description  Mencatat pembayaran untuk booking (permission payments:create)
+// @Tags         Payments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  int                 true  "Booking ID"
+// @Param        payment  body  dto.PaymentRequest  true  "Payment Data"
+// @Param        Idempotency-Key  header  string  false  "Key unik per pembayaran, request ulang dengan key yang sama mendapat response pertama"
+// @Success      201  {object}  models.Payment
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Failure      413  {object}  apperror.Response
+// @Failure      422  {object}  apperror.Response
+// @Router       /api/admin/bookings/{id}/payments [post]
+func CreatePayment(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	venueID, err := bookingVenueID(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := authorizeVenue(c, venueID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.PaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	p := models.Payment{
+		Amount:           req.Amount,
+		Method:           req.Method,
+		Reference:        req.Reference,
+		ReceivedBy:       c.GetInt("user_id"),
+		ReceivedByAPIKey: apiKeyID(c),
+	}
+	// Booking dicek lagi di INSERT yang sama supaya pembayaran tidak tercatat
+	// untuk booking yang dihapus setelah pengecekan venue di atas
+	var after []byte
+	err = config.DB.QueryRowContext(ctx, `
+		INSERT INTO payments (booking_id, amount, method, reference, received_by, received_by_api_key)
+		SELECT b.id, $2::integer, $3::varchar, $4::varchar, $5::integer, $6::integer
+		FROM bookings b WHERE b.id = $1 AND b.deleted_at IS NULL
+		RETURNING id, booking_id, created_at, to_jsonb(payments)
+	`, id, p.Amount, p.Method, p.Reference, optionalID(p.ReceivedBy), p.ReceivedByAPIKey).Scan(&p.ID, &p.BookingID, &p.CreatedAt, &after)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Booking not found"))
+		return
+	}
+	audit.Record(c, audit.ActionCreate, audit.EntityPayment, strconv.Itoa(p.ID), nil, after)
+	metrics.PaymentReceived(p.Method, p.Amount)
+
+	c.JSON(http.StatusCreated, p)
+}
+
+// GetBookingPayments godoc
+// @Summary      Get booking payments
+// @Description  Menampilkan pembayaran untuk booking (permission payments:read)
+// @Tags         Payments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Booking ID"
+// @Success      200  {array}   models.Payment
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/bookings/{id}/payments [get]
+func GetBookingPayments(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	venueID, err := bookingVenueID(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := authorizeVenue(c, venueID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT id, booking_id, amount, method, reference, COALESCE(received_by, 0), received_by_api_key, created_at
+		FROM payments WHERE booking_id = $1 ORDER BY created_at
+	`, id)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer rows.Close()
+
+	payments := []models.Payment{}
+	for rows.Next() {
+		var p models.Payment
+		if err := rows.Scan(&p.ID, &p.BookingID, &p.Amount, &p.Method, &p.Reference, &p.ReceivedBy, &p.ReceivedByAPIKey, &p.CreatedAt); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		payments = append(payments, p)
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
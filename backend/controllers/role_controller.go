@@ -0,0 +1,403 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/audit"
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/HenryKristofani/GoFutsal/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoles godoc
+// @Summary      Get all roles
+// @Description  Menampilkan semua role beserta permission-nya (permission roles:manage)
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.Role
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /api/admin/roles [get]
+func GetRoles(c *gin.Context) {
+	ctx := c.Request.Context()
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT r.name, r.description, r.built_in, r.mfa_required, rp.permission
+		FROM roles r LEFT JOIN role_permissions rp ON rp.role = r.name
+		ORDER BY r.name, rp.permission
+	`)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer rows.Close()
+
+	roles := []models.Role{}
+	for rows.Next() {
+		var r models.Role
+		var permission sql.NullString
+		if err := rows.Scan(&r.Name, &r.Description, &r.BuiltIn, &r.MFARequired, &permission); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+
+		if len(roles) == 0 || roles[len(roles)-1].Name != r.Name {
+			r.Permissions = []string{}
+			roles = append(roles, r)
+		}
+		if permission.Valid {
+			last := &roles[len(roles)-1]
+			last.Permissions = append(last.Permissions, permission.String)
+		}
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetPermissions godoc
+// @Summary      Get all permissions
+// @Description  Menampilkan semua permission yang tersedia (permission roles:manage)
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.Permission
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /api/admin/permissions [get]
+func GetPermissions(c *gin.Context) {
+	ctx := c.Request.Context()
+	rows, err := config.DB.QueryContext(ctx, "SELECT name, description FROM permissions ORDER BY name")
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer rows.Close()
+
+	permissions := []models.Permission{}
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.Name, &p.Description); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		permissions = append(permissions, p)
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// CreateRole godoc
+// @Summary      Create role
+// @Description  Membuat role baru dengan daftar permission (permission roles:manage)
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        role  body  dto.RoleRequest  true  "Role Data"
+// @Success      201  {object}  models.Role
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Router       /api/admin/roles [post]
+func CreateRole(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO roles (name, description) VALUES ($1, $2)", req.Name, req.Description)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Role not found"))
+		return
+	}
+	if err := replaceRolePermissions(ctx, tx, req.Name, req.Permissions); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	rbac.Invalidate()
+
+	role := models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+	recordRole(c, audit.ActionCreate, role.Name, nil, &role)
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRolePermissions godoc
+// @Summary      Update role permissions
+// @Description  Mengganti semua permission milik role (permission roles:manage)
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name         path  string                      true  "Role name"
+// @Param        permissions  body  dto.RolePermissionsRequest  true  "Permissions"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/roles/{name}/permissions [put]
+func UpdateRolePermissions(c *gin.Context) {
+	ctx := c.Request.Context()
+	name := c.Param("name")
+
+	var req dto.RolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	// Admin harus selalu bisa mengelola role, supaya tidak terkunci
+	if name == models.RoleAdmin {
+		c.Error(apperror.Forbidden("Permissions of the admin role cannot be changed"))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	// Permission lama dikunci bersama baris role untuk dicatat di audit log
+	var previous []byte
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE((SELECT jsonb_agg(permission ORDER BY permission) FROM role_permissions WHERE role = r.name), '[]')
+		FROM roles r WHERE r.name = $1 FOR UPDATE
+	`, name).Scan(&previous)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Role not found"))
+		return
+	}
+	before := models.Role{Name: name}
+	if err := json.Unmarshal(previous, &before.Permissions); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	if err := replaceRolePermissions(ctx, tx, name, req.Permissions); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	rbac.Invalidate()
+	recordRole(c, audit.ActionUpdate, name, &before, &models.Role{Name: name, Permissions: req.Permissions})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role permissions updated successfully"})
+}
+
+// UpdateRoleMFA godoc
+// @Summary      Require 2FA for role
+// @Description  Mewajibkan (atau tidak) 2FA untuk semua user dengan role ini. User yang belum
+// @Description  mengaktifkan 2FA tidak bisa memakai route admin sampai 2FA aktif. (permission roles:manage)
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path  string              true  "Role name"
+// @Param        mfa   body  dto.RoleMFARequest  true  "Wajib 2FA"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/roles/{name}/mfa [put]
+func UpdateRoleMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.RoleMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	var before, after []byte
+	err := config.DB.QueryRowContext(ctx, `
+		UPDATE roles r SET mfa_required = $1 FROM roles old
+		WHERE r.name = $2 AND old.name = r.name
+		RETURNING to_jsonb(old), to_jsonb(r)
+	`, *req.Required, c.Param("name")).Scan(&before, &after)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Role not found"))
+		return
+	}
+	audit.Record(c, audit.ActionUpdate, audit.EntityRole, c.Param("name"), before, after)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role 2FA requirement updated successfully"})
+}
+
+// DeleteRole godoc
+// @Summary      Delete role
+// @Description  Menghapus role buatan admin. Role bawaan dan role yang masih dipakai user tidak bisa dihapus. (permission roles:manage)
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path  string  true  "Role name"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Router       /api/admin/roles/{name} [delete]
+func DeleteRole(c *gin.Context) {
+	ctx := c.Request.Context()
+	var builtIn bool
+	err := config.DB.QueryRowContext(ctx, "SELECT built_in FROM roles WHERE name = $1", c.Param("name")).Scan(&builtIn)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Role not found"))
+		return
+	}
+	if builtIn {
+		c.Error(apperror.Forbidden("Built-in roles cannot be deleted"))
+		return
+	}
+
+	var before []byte
+	err = config.DB.QueryRowContext(ctx, "DELETE FROM roles WHERE name = $1 RETURNING to_jsonb(roles)", c.Param("name")).Scan(&before)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Role not found"))
+		return
+	}
+	rbac.Invalidate()
+	audit.Record(c, audit.ActionDelete, audit.EntityRole, c.Param("name"), before, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// AssignUserRole godoc
+// @Summary      Assign role to user
+// @Description  Mengganti role user (permission roles:manage). Semua session user dicabut
+// @Description  supaya token dengan role lama tidak bisa dipakai lagi; user harus login ulang.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path  int                    true  "User ID"
+// @Param        role  body  dto.AssignRoleRequest  true  "Role"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/users/{id}/role [put]
+func AssignUserRole(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	if err := validateRole(ctx, req.Role); err != nil {
+		c.Error(err)
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	var userID int
+	var changed bool
+	var before, after []byte
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users u SET role = $1 FROM users old
+		WHERE u.id = $2 AND u.deleted_at IS NULL AND old.id = u.id
+		RETURNING u.id, old.role <> u.role, to_jsonb(old), to_jsonb(u)
+	`, req.Role, c.Param("id")).Scan(&userID, &changed, &before, &after)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "User not found"))
+		return
+	}
+
+	// Role ada di claim access token, jadi token lama harus dicabut bersama sessionnya
+	if changed {
+		if err := auth.RevokeSessions(ctx, tx, userID, ""); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	audit.Record(c, audit.ActionUpdate, audit.EntityUser, c.Param("id"), before, after)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User role updated successfully"})
+}
+
+// recordRole mencatat perubahan role beserta daftar permission-nya di audit log
+func recordRole(c *gin.Context, action, name string, before, after *models.Role) {
+	audit.Record(c, action, audit.EntityRole, name, roleJSON(before), roleJSON(after))
+}
+
+// roleJSON mengubah role menjadi JSON untuk audit log, nil jika role nil
+func roleJSON(r *models.Role) []byte {
+	if r == nil {
+		return nil
+	}
+	data, _ := json.Marshal(r) // hanya string dan bool, tidak bisa gagal
+	return data
+}
+
+// validateRole memastikan role yang akan diberikan ke user ada di tabel roles
+func validateRole(ctx context.Context, role string) error {
+	var exists bool
+	if err := config.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)", role).Scan(&exists); err != nil {
+		return apperror.Internal(err)
+	}
+	if !exists {
+		return apperror.Validation(apperror.FieldError{Field: "role", Message: "role does not exist"})
+	}
+	return nil
+}
+
+// replaceRolePermissions mengganti permission role di dalam transaksi
+func replaceRolePermissions(ctx context.Context, tx *sql.Tx, role string, permissions []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE role = $1", role); err != nil {
+		return apperror.Internal(err)
+	}
+
+	for _, p := range permissions {
+		_, err := tx.ExecContext(ctx, "INSERT INTO role_permissions (role, permission) VALUES ($1, $2) ON CONFLICT DO NOTHING", role, p)
+		if err != nil {
+			if appErr := apperror.FromDB(err, ""); appErr.Code == apperror.CodeConflict {
+				return apperror.Validation(apperror.FieldError{Field: "permissions", Message: "unknown permission " + p})
+			}
+			return apperror.Internal(err)
+		}
+	}
+	return nil
+}
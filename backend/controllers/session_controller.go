@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/audit"
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSessions godoc
+// @Summary      Get active sessions
+// @Description  Menampilkan perangkat tempat user sedang login
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.Session
+// @Failure      401  {object}  apperror.Response
+// @Router       /api/profile/sessions [get]
+func GetSessions(c *gin.Context) {
+	sessions, err := activeSessions(c.Request.Context(), c.GetInt("user_id"), c.GetString("session_id"))
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+// @Summary      Revoke session
+// @Description  Logout dari satu perangkat. Access token dan refresh token session tersebut langsung tidak berlaku.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Session ID"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/profile/sessions/{id} [delete]
+func RevokeSession(c *gin.Context) {
+	revoked, err := auth.RevokeSession(c.Request.Context(), c.Param("id"), c.GetInt("user_id"))
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if !revoked {
+		c.Error(apperror.NotFound("Session not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// GetUserSessions godoc
+// @Summary      Get user sessions
+// @Description  Menampilkan session aktif milik user (permission users:manage)
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "User ID"
+// @Success      200  {array}   models.Session
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/users/{id}/sessions [get]
+func GetUserSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	var userID int
+	err := config.DB.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1 AND deleted_at IS NULL", c.Param("id")).Scan(&userID)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "User not found"))
+		return
+	}
+
+	sessions, err := activeSessions(ctx, userID, c.GetString("session_id"))
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// ForceLogoutUser godoc
+// @Summary      Force logout user
+// @Description  Mencabut semua session user sehingga user harus login ulang di semua perangkat (permission users:manage)
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "User ID"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/users/{id}/sessions [delete]
+func ForceLogoutUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	var userID int
+	err := config.DB.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1 AND deleted_at IS NULL", c.Param("id")).Scan(&userID)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "User not found"))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	if err := auth.RevokeSessions(ctx, tx, userID, ""); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	audit.Record(c, audit.ActionForceLogout, audit.EntityUser, strconv.Itoa(userID), nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User logged out from all sessions"})
+}
+
+// activeSessions mengambil session user yang belum dicabut dan belum kedaluwarsa
+func activeSessions(ctx context.Context, userID int, currentSession string) ([]models.Session, error) {
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT id, user_agent, ip_address, created_at, last_seen_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		s.Current = s.ID == currentSession
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
@@ -2,90 +2,189 @@ package controllers
 
 import (
 	"database/sql"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/audit"
 	"github.com/HenryKristofani/GoFutsal/auth"
 	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/loginguard"
+	"github.com/HenryKristofani/GoFutsal/metrics"
 	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/HenryKristofani/GoFutsal/rbac"
+	"github.com/HenryKristofani/GoFutsal/tracing"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// LoginRequest represents the login credentials
-type LoginRequest struct {
-	Username string `json:"username" binding:"required" example:"johndoe"`
-	Password string `json:"password" binding:"required" example:"password123"`
-}
-
 // LoginResponse represents the login response
 type LoginResponse struct {
-	Success      bool        `json:"success" example:"true"`
-	Message      string      `json:"message" example:"Login successful"`
-	AccessToken  string      `json:"access_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	RefreshToken string      `json:"refresh_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	User         models.User `json:"user"`
+	Success      bool         `json:"success" example:"true"`
+	Message      string       `json:"message" example:"Login successful"`
+	AccessToken  string       `json:"access_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string       `json:"refresh_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	MFARequired  bool         `json:"mfa_required,omitempty" example:"false"`
+	MFAToken     string       `json:"mfa_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	User         *models.User `json:"user,omitempty"` // kosong selama 2FA belum diverifikasi
 }
 
 // Login godoc
 // @Summary      Login user
-// @Description  Login dengan username dan password
+// @Description  Login dengan username atau email (tidak membedakan huruf besar/kecil) dan password. Setelah beberapa kali gagal per akun
+// @Description  atau per IP ada jeda yang makin lama, lalu dikunci sementara (429 + Retry-After).
+// @Description  Jika 2FA aktif, response hanya berisi mfa_required dan mfa_token (tanpa data user)
+// @Description  untuk /api/auth/login/mfa.
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
-// @Param        credentials  body  LoginRequest  true  "Login Credentials"
+// @Param        credentials  body  dto.LoginRequest  true  "Login Credentials"
 // @Success      200  {object}  LoginResponse
-// @Failure      400  {object}  map[string]string
-// @Failure      401  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /api/auth/login [post]
 func Login(c *gin.Context) {
-	var loginReq LoginRequest
+	var loginReq dto.LoginRequest
 	if err := c.ShouldBindJSON(&loginReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username dan password harus diisi"})
+		c.Error(apperror.FromBinding(err))
 		return
 	}
 
-	// Query user dari database berdasarkan username
+	ctx := c.Request.Context()
+
+	// Query user berdasarkan username atau email, tanpa membedakan huruf besar/kecil.
+	// Username tidak boleh berisi "@", jadi paling banyak satu akun yang cocok;
+	// username tetap diutamakan untuk akun lama.
 	var user models.User
 	var hashedPassword string
-
-	query := `SELECT id, username, email, password, role FROM users WHERE username = $1`
-	err := config.DB.QueryRow(query, loginReq.Username).Scan(
-		&user.ID, &user.Username, &user.Email, &hashedPassword, &user.Role,
+	var mfaEnabled bool
+
+	query := `
+		SELECT id, username, email, password, role, email_verified_at IS NOT NULL, totp_enabled_at IS NOT NULL
+		FROM users
+		WHERE (LOWER(username) = LOWER($1) OR LOWER(email) = LOWER($1)) AND deleted_at IS NULL
+		ORDER BY LOWER(username) = LOWER($1) DESC
+		LIMIT 1`
+	err := config.DB.QueryRowContext(ctx, query, loginReq.Username).Scan(
+		&user.ID, &user.Username, &user.Email, &hashedPassword, &user.Role, &user.EmailVerified, &mfaEnabled,
 	)
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Username atau password salah"})
+	if err != nil && err != sql.ErrNoRows {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	// Limiter memakai username asli supaya login dengan email atau huruf berbeda
+	// tetap dihitung ke akun yang sama
+	account := loginReq.Username
+	if err == nil {
+		account = user.Username
+	}
+	if !loginAllowed(c, account) {
+		return
+	}
+
+	if err == sql.ErrNoRows {
+		// Tetap jalankan bcrypt supaya waktu response tidak membedakan username yang ada dan tidak
+		hashedPassword = string(dummyPasswordHash)
+	}
+
+	// Verifikasi password dengan bcrypt, diukur sebagai span sendiri karena paling mahal
+	_, span := tracing.Start(c.Request.Context(), "bcrypt.compare")
+	passwordErr := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(loginReq.Password))
+	span.End()
+	if passwordErr != nil || user.ID == 0 {
+		loginFailed(c, account, optionalID(user.ID), models.LoginInvalidCredentials)
+		c.Error(apperror.Unauthorized("Invalid username or password"))
+		return
+	}
+
+	// Akun dengan 2FA: access token baru diberikan setelah kode diverifikasi di /api/auth/login/mfa.
+	// Hitungan gagal login belum di-reset supaya tebakan kode 2FA tetap dibatasi.
+	if mfaEnabled {
+		mfaToken, err := auth.GenerateMFAToken(user.ID)
+		if err != nil {
+			c.Error(apperror.Internal(err))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Terjadi kesalahan pada server"})
+		recordLoginAttempt(c, account, &user.ID, false, models.LoginMFAPending)
+
+		c.JSON(http.StatusOK, LoginResponse{
+			Success:     true,
+			Message:     "Two-factor authentication required",
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		})
 		return
 	}
 
-	// Verifikasi password dengan bcrypt
-	err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(loginReq.Password))
+	if err := loginguard.RecordSuccess(ctx, account); err != nil {
+		slog.ErrorContext(ctx, "login guard failed", "error", err)
+	}
+	recordLoginAttempt(c, account, &user.ID, true, models.LoginSuccess)
+
+	issueLoginTokens(c, user)
+}
+
+// loginAllowed mengecek jeda/kunci login sebelum bcrypt supaya percobaan beruntun
+// tidak membebani server. Jika tidak boleh, response 429 sudah ditulis.
+func loginAllowed(c *gin.Context, username string) bool {
+	decision, err := loginguard.Check(c.Request.Context(), username, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Username atau password salah"})
+		c.Error(apperror.Internal(err))
+		return false
+	}
+	if decision.Allowed {
+		return true
+	}
+
+	reason := models.LoginThrottled
+	message := "Too many failed login attempts, please try again later"
+	if decision.Locked {
+		reason = models.LoginLocked
+		message = "Account temporarily locked due to too many failed login attempts"
+	}
+	metrics.LoginFailed(reason)
+	recordLoginAttempt(c, username, nil, false, reason)
+	c.Header("Retry-After", strconv.Itoa(int(decision.RetryAfter.Seconds())+1))
+	c.Error(apperror.TooManyRequests(message))
+	return false
+}
+
+// loginFailed mencatat login gagal ke limiter, metrics dan audit
+func loginFailed(c *gin.Context, username string, userID *int, reason string) {
+	metrics.LoginFailed(reason)
+	if err := loginguard.RecordFailure(c.Request.Context(), username, c.ClientIP()); err != nil {
+		slog.ErrorContext(c.Request.Context(), "login guard failed", "error", err)
+	}
+	recordLoginAttempt(c, username, userID, false, reason)
+}
+
+// issueLoginTokens membuat session baru beserta access token dan refresh token
+// untuk user yang berhasil login
+func issueLoginTokens(c *gin.Context, user models.User) {
+	ctx := c.Request.Context()
+	sessionID, err := auth.CreateSession(ctx, user.ID, requestUserAgent(c), c.ClientIP())
+	if err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
 
 	// Generate JWT tokens
-	accessToken, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	accessToken, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role, sessionID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to generate access token",
-		})
+		c.Error(apperror.Internal(err))
 		return
 	}
 
-	refreshToken, err := auth.GenerateRefreshToken(user.ID)
+	refreshToken, err := auth.GenerateRefreshToken(ctx, user.ID, sessionID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to generate refresh token",
-		})
+		c.Error(apperror.Internal(err))
 		return
 	}
 
@@ -94,24 +193,53 @@ func Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, LoginResponse{
 		Success:      true,
-		Message:      "Login berhasil",
+		Message:      "Login successful",
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		User:         user,
+		User:         &user,
 	})
 }
 
+// dummyPasswordHash dipakai untuk username yang tidak ada, lihat Login
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("gofutsal-dummy-password"), bcrypt.DefaultCost)
+
+// recordLoginAttempt mencatat percobaan login ke tabel audit login_attempts.
+// Gagal mencatat tidak menggagalkan login, hanya di-log.
+func recordLoginAttempt(c *gin.Context, username string, userID *int, success bool, reason string) {
+	ctx := c.Request.Context()
+	_, err := config.DB.ExecContext(ctx,
+		"INSERT INTO login_attempts (username, user_id, ip_address, user_agent, success, reason) VALUES ($1, $2, $3, $4, $5, $6)",
+		username, userID, c.ClientIP(), requestUserAgent(c), success, reason,
+	)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "record login attempt failed", "error", err)
+	}
+}
+
+// requestUserAgent mengembalikan User-Agent request, dipotong sesuai kolom user_agent
+func requestUserAgent(c *gin.Context) string {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = strings.ToValidUTF8(userAgent[:255], "")
+	}
+	return userAgent
+}
+
 // GetUsers godoc
 // @Summary      Get all users
-// @Description  Menampilkan semua user
+// @Description  Menampilkan semua user (permission users:manage)
 // @Tags         Users
 // @Produce      json
+// @Security     BearerAuth
 // @Success      200  {array}  models.User
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
 // @Router       /api/users [get]
 func GetUsers(c *gin.Context) {
-	rows, err := config.DB.Query("SELECT id, username, email, role FROM users ORDER BY id")
+	ctx := c.Request.Context()
+	rows, err := config.DB.QueryContext(ctx, "SELECT id, username, email, role, email_verified_at IS NOT NULL FROM users WHERE deleted_at IS NULL ORDER BY id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.Internal(err))
 		return
 	}
 	defer rows.Close()
@@ -119,8 +247,8 @@ func GetUsers(c *gin.Context) {
 	var users []models.User
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role, &u.EmailVerified); err != nil {
+			c.Error(apperror.Internal(err))
 			return
 		}
 		users = append(users, u)
@@ -136,15 +264,13 @@ func GetUsers(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Success      200  {object}  LoginResponse
-// @Failure      401  {object}  map[string]interface{}
+// @Failure      401  {object}  apperror.Response
 // @Router       /api/auth/refresh [post]
 func RefreshToken(c *gin.Context) {
+	ctx := c.Request.Context()
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"message": "Refresh token required",
-		})
+		c.Error(apperror.Unauthorized("Refresh token required"))
 		return
 	}
 
@@ -154,38 +280,33 @@ func RefreshToken(c *gin.Context) {
 		refreshToken = authHeader[7:]
 	}
 
-	claims, err := auth.ValidateToken(refreshToken)
+	claims, err := auth.ValidateRefreshToken(ctx, refreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"message": "Invalid refresh token: " + err.Error(),
-		})
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid or expired refresh token"))
 		return
 	}
 
 	// Get user data from database
 	var user models.User
 	var hashedPassword string
-	query := `SELECT id, username, email, password, role FROM users WHERE id = $1`
-	err = config.DB.QueryRow(query, claims.UserID).Scan(
-		&user.ID, &user.Username, &user.Email, &hashedPassword, &user.Role,
+	query := `SELECT id, username, email, password, role, email_verified_at IS NOT NULL FROM users WHERE id = $1 AND deleted_at IS NULL`
+	err = config.DB.QueryRowContext(ctx, query, claims.UserID).Scan(
+		&user.ID, &user.Username, &user.Email, &hashedPassword, &user.Role, &user.EmailVerified,
 	)
 
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"message": "User not found",
-		})
+		if err == sql.ErrNoRows {
+			c.Error(apperror.Unauthorized("User not found"))
+			return
+		}
+		c.Error(apperror.Internal(err))
 		return
 	}
 
 	// Generate new access token
-	newAccessToken, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	newAccessToken, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role, claims.SessionID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to generate new token",
-		})
+		c.Error(apperror.Internal(err))
 		return
 	}
 
@@ -195,7 +316,7 @@ func RefreshToken(c *gin.Context) {
 		Success:     true,
 		Message:     "Token refreshed successfully",
 		AccessToken: newAccessToken,
-		User:        user,
+		User:        &user,
 	})
 }
 
@@ -207,37 +328,25 @@ func RefreshToken(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Success      200  {object}  models.User
-// @Failure      401  {object}  map[string]interface{}
-// @Failure      404  {object}  map[string]interface{}
+// @Failure      401  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
 // @Router       /api/profile [get]
 func GetProfile(c *gin.Context) {
+	ctx := c.Request.Context()
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"message": "User not authenticated",
-		})
+		c.Error(apperror.Unauthorized("User not authenticated"))
 		return
 	}
 
 	var user models.User
-	query := `SELECT id, username, email, role FROM users WHERE id = $1`
-	err := config.DB.QueryRow(query, userID).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Role,
+	query := `SELECT id, username, email, role, email_verified_at IS NOT NULL FROM users WHERE id = $1 AND deleted_at IS NULL`
+	err := config.DB.QueryRowContext(ctx, query, userID).Scan(
+		&user.ID, &user.Username, &user.Email, &user.Role, &user.EmailVerified,
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{
-				"success": false,
-				"message": "User not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Database error",
-		})
+		c.Error(apperror.FromDB(err, "User not found"))
 		return
 	}
 
@@ -250,19 +359,24 @@ func GetProfile(c *gin.Context) {
 
 // GetUserByID godoc
 // @Summary      Get user by ID
-// @Description  Menampilkan user berdasarkan ID
+// @Description  Menampilkan user berdasarkan ID (akun sendiri atau permission users:manage)
 // @Tags         Users
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id   path      int  true  "User ID"
 // @Success      200  {object}  models.User
-// @Failure      404  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
 // @Router       /api/users/{id} [get]
 func GetUserByID(c *gin.Context) {
+	ctx := c.Request.Context()
 	id := c.Param("id")
 	var u models.User
-	err := config.DB.QueryRow("SELECT id, username, email, role FROM users WHERE id = $1", id).Scan(&u.ID, &u.Username, &u.Email, &u.Role)
+	err := config.DB.QueryRowContext(ctx, "SELECT id, username, email, role, email_verified_at IS NOT NULL FROM users WHERE id = $1 AND deleted_at IS NULL", id).
+		Scan(&u.ID, &u.Username, &u.Email, &u.Role, &u.EmailVerified)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(apperror.FromDB(err, "User not found"))
 		return
 	}
 	c.JSON(http.StatusOK, u)
@@ -270,95 +384,339 @@ func GetUserByID(c *gin.Context) {
 
 // RegisterUser godoc
 // @Summary      Register akun client
-// @Description  Membuat akun baru untuk client (role otomatis client)
+// @Description  Membuat akun baru untuk client (role otomatis client).
+// @Description  Akun belum terverifikasi dan belum bisa booking sampai link di email dibuka.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
-// @Param        user  body  models.User  true  "User Data (tanpa role, role otomatis client)"
+// @Param        user  body  dto.RegisterUserRequest  true  "User Data (tanpa role, role otomatis client)"
 // @Success      201  {object}  models.User
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /api/users/register [post]
 func RegisterUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	ctx := c.Request.Context()
+	var req dto.RegisterUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
 		return
 	}
 	// Set role otomatis ke client
-	user.Role = "client"
+	user := models.User{
+		Username: req.Username,
+		Email:    req.Email,
+		Role:     "client",
+	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		c.Error(apperror.Internal(err))
 		return
 	}
 
-	query := `INSERT INTO users (username, email, password, role) VALUES ($1, $2, $3, $4) RETURNING id`
-	err = config.DB.QueryRow(query, user.Username, user.Email, string(hashedPassword), user.Role).Scan(&user.ID)
+	// Akun baru belum terverifikasi, link verifikasi dikirim ke email
+	query := `INSERT INTO users (username, email, password, role, verification_sent_at) VALUES ($1, $2, $3, $4, NOW()) RETURNING id, to_jsonb(users)`
+	var after []byte
+	err = config.DB.QueryRowContext(ctx, query, user.Username, user.Email, string(hashedPassword), user.Role).Scan(&user.ID, &after)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.FromDB(err, "User not found"))
 		return
 	}
+	audit.Record(c, audit.ActionCreate, audit.EntityUser, strconv.Itoa(user.ID), nil, after)
+	sendVerificationEmailAsync(c.Request.Context(), user.ID, user.Email)
+
 	c.JSON(http.StatusCreated, user)
 }
 
 // UpdateUser godoc
 // @Summary      Update user
-// @Description  Memperbarui data user berdasarkan ID
+// @Description  Memperbarui data user berdasarkan ID (akun sendiri atau permission users:manage).
+// @Description  Password diganti lewat /api/profile/password, role hanya bisa diubah dengan permission roles:manage
+// @Description  dan mengganti role mencabut semua session user. Mengubah akun staff lain (role dengan permission)
+// @Description  juga butuh roles:manage.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id    path      int         true  "User ID"
-// @Param        user  body      models.User true  "User Data"
+// @Param        user  body      dto.UpdateUserRequest true  "User Data"
 // @Success      200   {object}  map[string]string
-// @Failure      400   {object}  map[string]string
-// @Failure      404   {object}  map[string]string
-// @Failure      500   {object}  map[string]string
+// @Failure      400   {object}  apperror.Response
+// @Failure      401   {object}  apperror.Response
+// @Failure      403   {object}  apperror.Response
+// @Failure      404   {object}  apperror.Response
+// @Failure      409   {object}  apperror.Response
+// @Failure      500   {object}  apperror.Response
 // @Router       /api/users/{id} [put]
 func UpdateUser(c *gin.Context) {
+	ctx := c.Request.Context()
 	id := c.Param("id")
-	var u models.User
+	var u dto.UpdateUserRequest
 	if err := c.ShouldBindJSON(&u); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.FromBinding(err))
 		return
 	}
-	query := `UPDATE users SET username=$1, email=$2, password=$3, role=$4 WHERE id=$5`
-	res, err := config.DB.Exec(query, u.Username, u.Email, u.Password, u.Role, id)
+
+	var currentRole string
+	err := config.DB.QueryRowContext(ctx, "SELECT role FROM users WHERE id = $1 AND deleted_at IS NULL", id).Scan(&currentRole)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.FromDB(err, "User not found"))
 		return
 	}
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+
+	// Mengganti role (termasuk role sendiri) sama dengan AssignUserRole,
+	// jadi butuh permission yang sama supaya user tidak bisa menaikkan hak aksesnya.
+	// Mengubah akun staff lain (role yang punya permission) juga butuh roles:manage,
+	// karena mengganti email-nya bisa dipakai untuk mengambil alih akun lewat reset password.
+	roleChange := u.Role != "" && u.Role != currentRole
+	privileged, err := isPrivilegedTarget(c, id, currentRole)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if roleChange || privileged {
+		allowed, err := rbac.ContextHasPermission(c, rbac.RolesManage)
+		if err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		if !allowed {
+			c.Error(apperror.Forbidden("Missing permission: " + rbac.RolesManage))
+			return
+		}
+	}
+
+	role := currentRole
+	if roleChange {
+		if err := validateRole(ctx, u.Role); err != nil {
+			c.Error(err)
+			return
+		}
+		role = u.Role
+	}
+
+	if err := updateAccount(c, id, u.Username, u.Email, role); err != nil {
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User updated successfully"})
 }
 
+// isPrivilegedTarget mengecek apakah user yang diubah adalah akun lain dengan role yang punya permission
+func isPrivilegedTarget(c *gin.Context, id, role string) (bool, error) {
+	if id == strconv.Itoa(c.GetInt("user_id")) {
+		return false, nil
+	}
+	permissions, err := rbac.RolePermissions(c.Request.Context(), role)
+	if err != nil {
+		return false, err
+	}
+	return len(permissions) > 0, nil
+}
+
+// UpdateProfile godoc
+// @Summary      Update current user profile
+// @Description  Memperbarui username dan email milik user yang login.
+// @Description  Email yang diganti harus diverifikasi ulang.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        profile  body  dto.UpdateProfileRequest  true  "Profile Data"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Router       /api/profile [put]
+func UpdateProfile(c *gin.Context) {
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	if err := updateAccount(c, strconv.Itoa(c.GetInt("user_id")), req.Username, req.Email, ""); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully"})
+}
+
+// updateAccount memperbarui username, email dan role (kosong = tidak diubah).
+// Jika email diganti, akun kembali belum terverifikasi dan link verifikasi baru dikirim.
+// Jika role diganti, semua session user dicabut seperti AssignUserRole.
+func updateAccount(c *gin.Context, userID string, username, email, role string) error {
+	ctx := c.Request.Context()
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	var emailChanged, roleChanged bool
+	var before, after []byte
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users u SET
+			username = $1,
+			email = $2,
+			role = COALESCE(NULLIF($3, ''), u.role),
+			email_verified_at = CASE WHEN old.email = $2 THEN old.email_verified_at END,
+			verification_sent_at = CASE WHEN old.email = $2 THEN old.verification_sent_at ELSE NOW() END
+		FROM users old
+		WHERE u.id = $4 AND u.deleted_at IS NULL AND old.id = u.id
+		RETURNING u.id, old.email <> u.email, old.role <> u.role, to_jsonb(old), to_jsonb(u)
+	`, username, email, role, userID).Scan(&id, &emailChanged, &roleChanged, &before, &after)
+	if err != nil {
+		return apperror.FromDB(err, "User not found")
+	}
+	if roleChanged {
+		if err := auth.RevokeSessions(ctx, tx, id, ""); err != nil {
+			return apperror.Internal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return apperror.Internal(err)
+	}
+	audit.Record(c, audit.ActionUpdate, audit.EntityUser, userID, before, after)
+
+	if emailChanged {
+		sendVerificationEmailAsync(c.Request.Context(), id, email)
+	}
+	return nil
+}
+
+// ChangePassword godoc
+// @Summary      Change password
+// @Description  Mengganti password user yang login, wajib menyertakan password lama.
+// @Description  Semua session lain user dicabut (logout dari perangkat lain).
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        password  body  dto.ChangePasswordRequest  true  "Password lama dan baru"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/profile/password [put]
+func ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	var hashedPassword string
+	err := config.DB.QueryRowContext(ctx, "SELECT password FROM users WHERE id = $1 AND deleted_at IS NULL", userID).Scan(&hashedPassword)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "User not found"))
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.CurrentPassword)); err != nil {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "current_password", Message: "current_password is incorrect"}))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	if err := setPassword(ctx, tx, userID, req.NewPassword, c.GetString("session_id")); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	audit.Record(c, audit.ActionPasswordChange, audit.EntityUser, strconv.Itoa(userID), nil, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully, please log in again on other devices"})
+}
+
 // DeleteUser godoc
 // @Summary      Delete user
-// @Description  Menghapus user berdasarkan ID
+// @Description  Menghapus (soft delete) user berdasarkan ID (akun sendiri atau permission users:manage).
+// @Description  Semua session user dicabut, booking lama tetap tersimpan.
 // @Tags         Users
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id   path      int  true  "User ID"
 // @Success      200  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /api/users/{id} [delete]
 func DeleteUser(c *gin.Context) {
+	ctx := c.Request.Context()
 	id := c.Param("id")
-	res, err := config.DB.Exec("DELETE FROM users WHERE id = $1", id)
+
+	tx, err := config.DB.BeginTx(ctx, nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.Internal(err))
 		return
 	}
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	defer tx.Rollback()
+
+	var userID int
+	var before []byte
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users u SET deleted_at = NOW() FROM users old
+		WHERE u.id = $1 AND u.deleted_at IS NULL AND old.id = u.id
+		RETURNING u.id, to_jsonb(old)
+	`, id).Scan(&userID, &before)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "User not found"))
+		return
+	}
+	if err := auth.RevokeSessions(ctx, tx, userID, ""); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
+	audit.Record(c, audit.ActionDelete, audit.EntityUser, id, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
+
+// RestoreUser godoc
+// @Summary      Restore user
+// @Description  Mengembalikan user yang dihapus (permission users:manage). User harus login ulang.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  models.User
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/users/{id}/restore [post]
+func RestoreUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	var user models.User
+	var after []byte
+	err := config.DB.QueryRowContext(ctx, `
+		UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING id, username, email, role, email_verified_at IS NOT NULL, to_jsonb(users)
+	`, c.Param("id")).Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.EmailVerified, &after)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Deleted user not found"))
+		return
+	}
+	audit.Record(c, audit.ActionRestore, audit.EntityUser, c.Param("id"), nil, after)
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,440 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/models"
+	"github.com/HenryKristofani/GoFutsal/venuetime"
+	"github.com/gin-gonic/gin"
+)
+
+// GetVenues godoc
+// @Summary      Get all venues
+// @Description  Menampilkan semua venue futsal
+// @Tags         Venues
+// @Produce      json
+// @Success      200  {array}  models.Venue
+// @Router       /api/venues [get]
+func GetVenues(c *gin.Context) {
+	ctx := c.Request.Context()
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT id, name, address, latitude, longitude, timezone, opening_time, closing_time, phone, email
+		FROM venues ORDER BY id
+	`)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer rows.Close()
+
+	venues := []models.Venue{}
+	for rows.Next() {
+		var v models.Venue
+		if err := rows.Scan(&v.ID, &v.Name, &v.Address, &v.Latitude, &v.Longitude, &v.Timezone,
+			&v.OpeningTime, &v.ClosingTime, &v.Phone, &v.Email); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		venues = append(venues, v)
+	}
+
+	c.JSON(http.StatusOK, venues)
+}
+
+// GetVenueByID godoc
+// @Summary      Get venue by ID
+// @Description  Menampilkan detail venue berdasarkan ID
+// @Tags         Venues
+// @Produce      json
+// @Param        id   path      int  true  "Venue ID"
+// @Success      200  {object}  models.Venue
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/venues/{id} [get]
+func GetVenueByID(c *gin.Context) {
+	ctx := c.Request.Context()
+	var v models.Venue
+	err := config.DB.QueryRowContext(ctx, `
+		SELECT id, name, address, latitude, longitude, timezone, opening_time, closing_time, phone, email
+		FROM venues WHERE id = $1
+	`, c.Param("id")).Scan(&v.ID, &v.Name, &v.Address, &v.Latitude, &v.Longitude, &v.Timezone,
+		&v.OpeningTime, &v.ClosingTime, &v.Phone, &v.Email)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Venue not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// GetVenueCourts godoc
+// @Summary      Get courts of a venue
+// @Description  Menampilkan semua lapangan di venue tertentu
+// @Tags         Venues
+// @Produce      json
+// @Param        id   path      int  true  "Venue ID"
+// @Success      200  {array}   models.Court
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/venues/{id}/courts [get]
+func GetVenueCourts(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	var exists bool
+	if err := config.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM venues WHERE id = $1)", id).Scan(&exists); err != nil {
+		c.Error(apperror.FromDB(err, "Venue not found"))
+		return
+	}
+	if !exists {
+		c.Error(apperror.NotFound("Venue not found"))
+		return
+	}
+
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT id, venue_id, name, location, price_per_hour, is_available, timezone
+		FROM courts WHERE venue_id = $1 AND deleted_at IS NULL ORDER BY id
+	`, id)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer rows.Close()
+
+	courts := []models.Court{}
+	for rows.Next() {
+		var court models.Court
+		if err := rows.Scan(&court.ID, &court.VenueID, &court.Name, &court.Location, &court.PricePerHour, &court.IsAvailable, &court.Timezone); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		courts = append(courts, court)
+	}
+
+	c.JSON(http.StatusOK, courts)
+}
+
+// CreateVenue godoc
+// @Summary      Tambah venue baru
+// @Description  Menambahkan venue futsal baru (permission venues:admin)
+// @Tags         Venues
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        venue  body  dto.VenueRequest  true  "Venue Data"
+// @Success      201  {object}  models.Venue
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /api/admin/venues [post]
+func CreateVenue(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.VenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	v := venueFromRequest(req)
+	err := config.DB.QueryRowContext(ctx, `
+		INSERT INTO venues (name, address, latitude, longitude, timezone, opening_time, closing_time, phone, email)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, v.Name, v.Address, v.Latitude, v.Longitude, v.Timezone, v.OpeningTime, v.ClosingTime, v.Phone, v.Email).Scan(&v.ID)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Venue not found"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, v)
+}
+
+// UpdateVenue godoc
+// @Summary      Update venue
+// @Description  Memperbarui data venue. Timezone lapangan di venue ikut diperbarui. (permission venues:write, hanya venue yang ditugaskan)
+// @Tags         Venues
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path  int               true  "Venue ID"
+// @Param        venue  body  dto.VenueRequest  true  "Venue Data"
+// @Success      200  {object}  models.Venue
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/venues/{id} [put]
+func UpdateVenue(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, ok := venueIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := authorizeVenue(c, &id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.VenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	v := venueFromRequest(req)
+	v.ID = id
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE venues
+		SET name=$1, address=$2, latitude=$3, longitude=$4, timezone=$5, opening_time=$6, closing_time=$7, phone=$8, email=$9
+		WHERE id=$10
+	`, v.Name, v.Address, v.Latitude, v.Longitude, v.Timezone, v.OpeningTime, v.ClosingTime, v.Phone, v.Email, v.ID)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Venue not found"))
+		return
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		c.Error(apperror.NotFound("Venue not found"))
+		return
+	}
+
+	// Lapangan selalu memakai timezone venue-nya
+	if _, err := tx.ExecContext(ctx, "UPDATE courts SET timezone = $1 WHERE venue_id = $2", v.Timezone, v.ID); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// DeleteVenue godoc
+// @Summary      Delete venue
+// @Description  Menghapus venue. Venue yang masih memiliki lapangan tidak bisa dihapus. (permission venues:admin)
+// @Tags         Venues
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Venue ID"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Router       /api/admin/venues/{id} [delete]
+func DeleteVenue(c *gin.Context) {
+	ctx := c.Request.Context()
+	res, err := config.DB.ExecContext(ctx, "DELETE FROM venues WHERE id = $1", c.Param("id"))
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Venue not found"))
+		return
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		c.Error(apperror.NotFound("Venue not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Venue deleted successfully"})
+}
+
+// GetVenueManagers godoc
+// @Summary      Get venue staff
+// @Description  Menampilkan user (manager dan staff lain) yang ditugaskan di venue (permission venues:admin)
+// @Tags         Venues
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Venue ID"
+// @Success      200  {array}   models.User
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Router       /api/admin/venues/{id}/managers [get]
+func GetVenueManagers(c *gin.Context) {
+	ctx := c.Request.Context()
+	rows, err := config.DB.QueryContext(ctx, `
+		SELECT u.id, u.username, u.email, u.role
+		FROM venue_managers vm JOIN users u ON u.id = vm.user_id
+		WHERE vm.venue_id = $1 AND u.deleted_at IS NULL
+		ORDER BY u.id
+	`, c.Param("id"))
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Venue not found"))
+		return
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		users = append(users, u)
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// AddVenueManager godoc
+// @Summary      Assign venue staff
+// @Description  Menugaskan user (manager atau kasir) ke venue. User dengan role client otomatis menjadi venue_manager. (permission venues:admin)
+// @Tags         Venues
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  int                      true  "Venue ID"
+// @Param        manager  body  dto.VenueManagerRequest  true  "User yang ditugaskan"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/venues/{id}/managers [post]
+func AddVenueManager(c *gin.Context) {
+	ctx := c.Request.Context()
+	venueID, ok := venueIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.VenueManagerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	var role string
+	if err := tx.QueryRowContext(ctx, "SELECT role FROM users WHERE id = $1 AND deleted_at IS NULL", req.UserID).Scan(&role); err != nil {
+		c.Error(apperror.FromDB(err, "User not found"))
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO venue_managers (user_id, venue_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, req.UserID, venueID)
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Venue not found"))
+		return
+	}
+
+	if role == models.RoleClient {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET role = $1 WHERE id = $2", models.RoleVenueManager, req.UserID); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Venue manager assigned successfully"})
+}
+
+// RemoveVenueManager godoc
+// @Summary      Remove venue staff
+// @Description  Mencabut penugasan user dari venue. Venue manager yang tidak mengelola venue lain kembali menjadi client. (permission venues:admin)
+// @Tags         Venues
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  int  true  "Venue ID"
+// @Param        user_id  path  int  true  "User ID"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Router       /api/admin/venues/{id}/managers/{user_id} [delete]
+func RemoveVenueManager(c *gin.Context) {
+	ctx := c.Request.Context()
+	tx, err := config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM venue_managers WHERE venue_id = $1 AND user_id = $2", c.Param("id"), c.Param("user_id"))
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Venue manager not found"))
+		return
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		c.Error(apperror.NotFound("Venue manager not found"))
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users SET role = $1
+		WHERE id = $2 AND role = $3
+		  AND NOT EXISTS (SELECT 1 FROM venue_managers WHERE user_id = $2)
+	`, models.RoleClient, c.Param("user_id"), models.RoleVenueManager)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Venue manager removed successfully"})
+}
+
+// venueFromRequest mengisi models.Venue dari request, dengan timezone default jika kosong
+func venueFromRequest(req dto.VenueRequest) models.Venue {
+	v := models.Venue{
+		Name:        req.Name,
+		Address:     req.Address,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		Timezone:    req.Timezone,
+		OpeningTime: req.OpeningTime,
+		ClosingTime: req.ClosingTime,
+		Phone:       req.Phone,
+		Email:       req.Email,
+	}
+	if v.Timezone == "" {
+		v.Timezone = venuetime.Default().String()
+	}
+	return v
+}
+
+// venueIDParam memastikan venue :id ada dan mengembalikan ID-nya.
+// Jika tidak ada, error sudah dicatat ke context.
+func venueIDParam(c *gin.Context) (int, bool) {
+	ctx := c.Request.Context()
+	var id int
+	err := config.DB.QueryRowContext(ctx, "SELECT id FROM venues WHERE id = $1", c.Param("id")).Scan(&id)
+	if err == sql.ErrNoRows {
+		c.Error(apperror.NotFound("Venue not found"))
+		return 0, false
+	}
+	if err != nil {
+		c.Error(apperror.FromDB(err, "Venue not found"))
+		return 0, false
+	}
+	return id, true
+}
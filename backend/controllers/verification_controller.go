@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/HenryKristofani/GoFutsal/mailer"
+	"github.com/gin-gonic/gin"
+)
+
+// verificationResendInterval adalah jeda minimal antar email verifikasi
+const verificationResendInterval = time.Minute
+
+// VerifyEmail godoc
+// @Summary      Verify email
+// @Description  Memverifikasi email dengan token dari link yang dikirim saat registrasi
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.VerifyEmailRequest  true  "Token verifikasi"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Router       /api/auth/verify-email [post]
+func VerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	claims, err := auth.ValidateVerificationToken(req.Token)
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid or expired verification link"))
+		return
+	}
+
+	// Email harus sama dengan email saat link dibuat
+	res, err := config.DB.ExecContext(ctx,
+		"UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1 AND email = $2 AND deleted_at IS NULL",
+		claims.UserID, claims.Email,
+	)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		c.Error(apperror.BadRequest("Invalid or expired verification link"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Description  Mengirim ulang email verifikasi untuk user yang login. Dibatasi satu kali per menit.
+// @Tags         Authentication
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Router       /api/auth/resend-verification [post]
+func ResendVerification(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	// Cek dan catat waktu kirim dalam satu query supaya request paralel tidak lolos throttle
+	var email string
+	err := config.DB.QueryRowContext(ctx, `
+		UPDATE users SET verification_sent_at = NOW()
+		WHERE id = $1 AND email_verified_at IS NULL
+		  AND (verification_sent_at IS NULL OR verification_sent_at <= NOW() - $2 * INTERVAL '1 second')
+		RETURNING email
+	`, userID, int(verificationResendInterval.Seconds())).Scan(&email)
+
+	if err == sql.ErrNoRows {
+		var verified bool
+		var sentAt sql.NullTime
+		err := config.DB.QueryRowContext(ctx,
+			"SELECT email_verified_at IS NOT NULL, verification_sent_at FROM users WHERE id = $1", userID,
+		).Scan(&verified, &sentAt)
+		if err != nil {
+			c.Error(apperror.FromDB(err, "User not found"))
+			return
+		}
+		if verified {
+			c.Error(apperror.BadRequest("Email is already verified"))
+			return
+		}
+		if sentAt.Valid {
+			wait := time.Until(sentAt.Time.Add(verificationResendInterval))
+			c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		}
+		c.Error(apperror.TooManyRequests("Verification email was sent recently, please try again later"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	if err := sendVerificationEmail(userID, email); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+// sendVerificationEmail membuat link verifikasi bertanda tangan dan mengirimkannya lewat mailer
+func sendVerificationEmail(userID int, email string) error {
+	token, err := auth.GenerateVerificationToken(userID, email)
+	if err != nil {
+		return err
+	}
+
+	link := appURL("/verify-email?token=" + url.QueryEscape(token))
+	return mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Verify your GoFutsal email",
+		Body: fmt.Sprintf(
+			"Welcome to GoFutsal!\n\nOpen this link within %d hours to verify your email address:\n%s\n\nYou can browse courts right away, but booking requires a verified email.",
+			int(auth.VerificationTokenTTL.Hours()), link,
+		),
+	})
+}
+
+// sendVerificationEmailAsync mengirim email verifikasi di background, error hanya di-log.
+// ctx hanya dipakai untuk log (request ID), tidak ikut dibatalkan saat request selesai.
+func sendVerificationEmailAsync(ctx context.Context, userID int, email string) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := sendVerificationEmail(userID, email); err != nil {
+			slog.ErrorContext(ctx, "send verification email failed", "user_id", userID, "error", err)
+		}
+	}()
+}
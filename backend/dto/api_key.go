@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// APIKeyRequest adalah body untuk membuat API key
+type APIKeyRequest struct {
+	Name               string     `json:"name" binding:"required,max=100" example:"Kiosk meja depan"`
+	Permissions        []string   `json:"permissions" binding:"required,min=1,dive,required,max=100" example:"bookings:checkin,payments:create"`
+	VenueIDs           []int      `json:"venue_ids" binding:"dive,min=1" example:"1"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute" binding:"omitempty,min=1,max=10000" example:"60"`
+	ExpiresAt          *time.Time `json:"expires_at" example:"2027-01-01T00:00:00Z"`
+}
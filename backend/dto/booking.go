@@ -0,0 +1,14 @@
+package dto
+
+// BookingRequest adalah body untuk membuat atau memperbarui booking.
+// user_id dan total_price tidak diterima dari client: user_id diambil dari
+// token, total_price dihitung dari harga lapangan.
+// Tanggal dan jam memakai jam lokal venue (timezone lapangan). end_time yang
+// lebih kecil dari start_time berarti booking selesai setelah tengah malam.
+type BookingRequest struct {
+	CourtID      int    `json:"court_id" binding:"required,gt=0" example:"1"`
+	CustomerName string `json:"customer_name" binding:"required,max=100" example:"John Doe"`
+	BookingDate  string `json:"booking_date" binding:"required,bookingdate" example:"2025-12-31"`
+	StartTime    string `json:"start_time" binding:"required,timeofday" example:"19:00"`
+	EndTime      string `json:"end_time" binding:"required,timeofday,timerange=StartTime" example:"21:00"`
+}
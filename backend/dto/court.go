@@ -0,0 +1,12 @@
+package dto
+
+// CourtRequest adalah body untuk membuat atau memperbarui lapangan.
+// Jika venue_id diisi, timezone lapangan mengikuti timezone venue.
+type CourtRequest struct {
+	VenueID      int    `json:"venue_id" binding:"omitempty,gt=0" example:"1"`
+	Name         string `json:"name" binding:"required,max=100" example:"Lapangan A"`
+	Location     string `json:"location" binding:"required,max=255" example:"Jl. Sudirman No. 1"`
+	PricePerHour int    `json:"price_per_hour" binding:"required,gt=0" example:"150000"`
+	IsAvailable  bool   `json:"is_available" example:"true"`
+	Timezone     string `json:"timezone" binding:"omitempty,timezone" example:"Asia/Jakarta"`
+}
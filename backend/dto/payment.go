@@ -0,0 +1,8 @@
+package dto
+
+// PaymentRequest adalah body untuk mencatat pembayaran booking
+type PaymentRequest struct {
+	Amount    int    `json:"amount" binding:"required,gt=0" example:"150000"`
+	Method    string `json:"method" binding:"required,oneof=cash transfer qris card" example:"cash"`
+	Reference string `json:"reference" binding:"max=100" example:"TRX-20251231-001"`
+}
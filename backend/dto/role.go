@@ -0,0 +1,23 @@
+package dto
+
+// RoleRequest adalah body untuk membuat role baru
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50,username" example:"supervisor"`
+	Description string   `json:"description" binding:"max=255" example:"Supervisor shift malam"`
+	Permissions []string `json:"permissions" binding:"dive,required,max=100" example:"bookings:checkin,payments:create"`
+}
+
+// RolePermissionsRequest adalah body untuk mengganti permission milik role
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"dive,required,max=100" example:"bookings:checkin,payments:create"`
+}
+
+// AssignRoleRequest adalah body untuk mengganti role user
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required,max=50" example:"cashier"`
+}
+
+// RoleMFARequest adalah body untuk mewajibkan atau tidak mewajibkan 2FA pada role
+type RoleMFARequest struct {
+	Required *bool `json:"required" binding:"required" example:"true"`
+}
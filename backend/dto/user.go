@@ -0,0 +1,69 @@
+package dto
+
+// LoginRequest represents the login credentials.
+// Username boleh diisi username atau email.
+type LoginRequest struct {
+	Username string `json:"username" binding:"required,max=100" example:"johndoe"`
+	Password string `json:"password" binding:"required,max=72" example:"password123"`
+}
+
+// RegisterUserRequest adalah body untuk registrasi akun client
+type RegisterUserRequest struct {
+	Username string `json:"username" binding:"required,username" example:"johndoe"`
+	Email    string `json:"email" binding:"required,email,max=100" example:"john@example.com"`
+	Password string `json:"password" binding:"required,password" example:"Rahasia123"`
+}
+
+// UpdateUserRequest adalah body untuk memperbarui data user.
+// Role hanya boleh diubah oleh role dengan permission roles:manage.
+type UpdateUserRequest struct {
+	Username string `json:"username" binding:"required,username" example:"johndoe"`
+	Email    string `json:"email" binding:"required,email,max=100" example:"john@example.com"`
+	Role     string `json:"role" binding:"omitempty,max=50" example:"client"`
+}
+
+// UpdateProfileRequest adalah body untuk memperbarui profil sendiri
+type UpdateProfileRequest struct {
+	Username string `json:"username" binding:"required,username" example:"johndoe"`
+	Email    string `json:"email" binding:"required,email,max=100" example:"john@example.com"`
+}
+
+// ChangePasswordRequest adalah body untuk mengganti password sendiri
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"Rahasia123"`
+	NewPassword     string `json:"new_password" binding:"required,password,nefield=CurrentPassword" example:"RahasiaBaru456"`
+}
+
+// ForgotPasswordRequest adalah body untuk meminta link reset password
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email,max=100" example:"john@example.com"`
+}
+
+// ResetPasswordRequest adalah body untuk mengganti password dengan token reset
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required,max=100" example:"q3Yd6n0y2x..."`
+	NewPassword string `json:"new_password" binding:"required,password" example:"RahasiaBaru456"`
+}
+
+// VerifyEmailRequest adalah body untuk verifikasi email dengan token dari link
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+// MFACodeRequest adalah body berisi kode 6 digit dari aplikasi authenticator
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456"`
+}
+
+// MFALoginRequest adalah body untuk langkah kedua login. Code bisa berupa
+// kode authenticator atau kode pemulihan.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code     string `json:"code" binding:"required,max=20" example:"123456"`
+}
+
+// DisableMFARequest adalah body untuk menonaktifkan 2FA
+type DisableMFARequest struct {
+	Password string `json:"password" binding:"required" example:"Rahasia123"`
+	Code     string `json:"code" binding:"required,max=20" example:"123456"`
+}
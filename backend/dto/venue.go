@@ -0,0 +1,19 @@
+package dto
+
+// VenueRequest adalah body untuk membuat atau memperbarui venue
+type VenueRequest struct {
+	Name        string   `json:"name" binding:"required,max=100" example:"GoFutsal Sudirman"`
+	Address     string   `json:"address" binding:"required,max=255" example:"Jl. Sudirman No. 1, Jakarta"`
+	Latitude    *float64 `json:"latitude" binding:"omitempty,latitude" example:"-6.2088"`
+	Longitude   *float64 `json:"longitude" binding:"omitempty,longitude" example:"106.8456"`
+	Timezone    string   `json:"timezone" binding:"omitempty,timezone" example:"Asia/Jakarta"`
+	OpeningTime string   `json:"opening_time" binding:"required,timeofday" example:"08:00"`
+	ClosingTime string   `json:"closing_time" binding:"required,timeofday,nefield=OpeningTime" example:"23:00"`
+	Phone       string   `json:"phone" binding:"omitempty,max=30" example:"+62215550100"`
+	Email       string   `json:"email" binding:"omitempty,email,max=100" example:"sudirman@gofutsal.id"`
+}
+
+// VenueManagerRequest adalah body untuk menugaskan user sebagai venue manager
+type VenueManagerRequest struct {
+	UserID int `json:"user_id" binding:"required,gt=0" example:"2"`
+}
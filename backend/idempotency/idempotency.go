@@ -0,0 +1,151 @@
+// Package idempotency menyimpan response pertama untuk setiap Idempotency-Key
+// supaya request yang dikirim ulang (misalnya karena koneksi putus atau tombol
+// ditekan dua kali) tidak diproses dua kali. Disimpan di database supaya berlaku
+// di semua instance aplikasi.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/config"
+)
+
+const (
+	// TTL adalah lama key disimpan; setelah itu key boleh dipakai untuk request baru
+	TTL = 24 * time.Hour
+	// LockTimeout adalah batas request yang masih diproses. Lock yang lebih lama
+	// dari ini dianggap ditinggalkan (misalnya instance mati) dan boleh diambil alih.
+	LockTimeout = time.Minute
+)
+
+// Error saat key sudah dipakai
+var (
+	ErrMismatch   = errors.New("idempotency key reused with a different request")
+	ErrInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+// Response adalah response tersimpan yang di-replay untuk request ulang.
+// Header ikut disimpan supaya replay membawa Content-Type, Location, dan sebagainya.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Fingerprint menghitung sidik request dari method, path dan body.
+// Request ulang dengan key yang sama harus punya fingerprint yang sama.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lastSweep adalah waktu terakhir key kadaluarsa dihapus oleh instance ini
+var lastSweep atomic.Int64
+
+// Begin mengambil key untuk request dengan fingerprint tersebut. Mengembalikan
+// response tersimpan jika request yang sama sudah selesai, nil jika pemanggil
+// harus memproses request lalu memanggil Complete atau Release. Error ErrMismatch
+// jika key dipakai untuk request lain dan ErrInProgress jika masih diproses.
+func Begin(ctx context.Context, scope, key, fingerprint string) (*Response, error) {
+	now := time.Now()
+	sweep(ctx, now)
+
+	// Insert atomic: hanya satu request paralel yang mendapat baris. Key yang sudah
+	// kadaluarsa atau lock yang ditinggalkan (dengan fingerprint sama) diambil alih.
+	var acquired bool
+	err := config.DB.QueryRowContext(ctx, `
+		INSERT INTO idempotency_keys (scope, key, fingerprint, locked_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = NULL,
+			response_headers = '{}',
+			response_body = NULL,
+			locked_at = EXCLUDED.locked_at,
+			created_at = EXCLUDED.locked_at,
+			completed_at = NULL
+		WHERE idempotency_keys.created_at < $5
+			OR (idempotency_keys.completed_at IS NULL AND idempotency_keys.locked_at < $6
+				AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+		RETURNING true
+	`, scope, key, fingerprint, now, now.Add(-TTL), now.Add(-LockTimeout)).Scan(&acquired)
+	if err == nil {
+		return nil, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	var (
+		stored     string
+		statusCode sql.NullInt64
+		headers    []byte
+		body       []byte
+	)
+	err = config.DB.QueryRowContext(ctx, `
+		SELECT fingerprint, status_code, response_headers, response_body
+		FROM idempotency_keys WHERE scope = $1 AND key = $2
+	`, scope, key).Scan(&stored, &statusCode, &headers, &body)
+	if err == sql.ErrNoRows {
+		// Baris baru saja dilepas request lain, client bisa langsung mencoba lagi
+		return nil, ErrInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if stored != fingerprint {
+		return nil, ErrMismatch
+	}
+	if !statusCode.Valid {
+		return nil, ErrInProgress
+	}
+	res := &Response{StatusCode: int(statusCode.Int64), Body: body}
+	if err := json.Unmarshal(headers, &res.Header); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Complete menyimpan response untuk key yang diambil dengan Begin
+func Complete(ctx context.Context, scope, key string, res Response) error {
+	headers, err := json.Marshal(res.Header)
+	if err != nil {
+		return err
+	}
+	_, err = config.DB.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $3, response_headers = $4, response_body = $5, completed_at = NOW()
+		WHERE scope = $1 AND key = $2
+	`, scope, key, res.StatusCode, string(headers), res.Body)
+	return err
+}
+
+// Release melepas key tanpa menyimpan response (request gagal), sehingga
+// client boleh mengirim ulang dengan key yang sama
+func Release(ctx context.Context, scope, key string) error {
+	_, err := config.DB.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND completed_at IS NULL", scope, key)
+	return err
+}
+
+// sweep menghapus key kadaluarsa, paling sering sekali per jam untuk instance ini
+func sweep(ctx context.Context, now time.Time) {
+	last := lastSweep.Load()
+	if now.Sub(time.Unix(0, last)) < time.Hour || !lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	if _, err := config.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE created_at < $1", now.Add(-TTL)); err != nil {
+		slog.WarnContext(ctx, "idempotency key sweep failed", "error", err)
+	}
+}
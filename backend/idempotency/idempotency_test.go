@@ -0,0 +1,157 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HenryKristofani/GoFutsal/config"
+)
+
+func newMockDB(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := config.DB
+	config.DB = db
+	// Sweep baru saja jalan, jadi Begin tidak menjalankan DELETE
+	lastSweep.Store(time.Now().UnixNano())
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		config.DB = prev
+		db.Close()
+	})
+	return mock
+}
+
+// timeAgo cocok dengan argumen waktu sekitar d sebelum sekarang
+type timeAgo time.Duration
+
+func (d timeAgo) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	want := time.Now().Add(-time.Duration(d))
+	return ok && t.After(want.Add(-time.Minute)) && t.Before(want.Add(time.Second))
+}
+
+func TestBeginAcquiresKey(t *testing.T) {
+	mock := newMockDB(t)
+	fp := Fingerprint("POST", "/api/bookings", []byte(`{"court_id":1}`))
+
+	// Key kadaluarsa (created_at < $5) diambil alih walaupun fingerprint berbeda,
+	// lock yang ditinggalkan (locked_at < $6) hanya untuk fingerprint yang sama
+	mock.ExpectQuery(`ON CONFLICT \(scope, key\) DO UPDATE .* WHERE idempotency_keys.created_at < \$5 `+
+		`OR \(idempotency_keys.completed_at IS NULL AND idempotency_keys.locked_at < \$6 `+
+		`AND idempotency_keys.fingerprint = EXCLUDED.fingerprint\)`).
+		WithArgs("user:5", "key-1", fp, timeAgo(0), timeAgo(TTL), timeAgo(LockTimeout)).
+		WillReturnRows(sqlmock.NewRows([]string{"bool"}).AddRow(true))
+
+	stored, err := Begin(context.Background(), "user:5", "key-1", fp)
+	if err != nil || stored != nil {
+		t.Fatalf("Begin = %v, %v; want nil, nil", stored, err)
+	}
+}
+
+func TestBeginExistingKey(t *testing.T) {
+	fp := Fingerprint("POST", "/api/bookings", []byte(`{"court_id":1}`))
+	columns := []string{"fingerprint", "status_code", "response_headers", "response_body"}
+
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		want    *Response
+		wantErr error
+	}{
+		{
+			name: "completed",
+			rows: sqlmock.NewRows(columns).AddRow(fp, 201,
+				[]byte(`{"Content-Type":["application/json"],"Location":["/api/bookings/7"]}`), []byte(`{"id":7}`)),
+			want: &Response{
+				StatusCode: 201,
+				Header:     http.Header{"Content-Type": {"application/json"}, "Location": {"/api/bookings/7"}},
+				Body:       []byte(`{"id":7}`),
+			},
+		},
+		{
+			name:    "different request",
+			rows:    sqlmock.NewRows(columns).AddRow(Fingerprint("POST", "/api/bookings", []byte(`{"court_id":2}`)), 201, []byte(`{}`), []byte(`{}`)),
+			wantErr: ErrMismatch,
+		},
+		{
+			name:    "still processing",
+			rows:    sqlmock.NewRows(columns).AddRow(fp, nil, []byte(`{}`), nil),
+			wantErr: ErrInProgress,
+		},
+		{
+			name:    "released meanwhile",
+			rows:    sqlmock.NewRows(columns),
+			wantErr: ErrInProgress,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockDB(t)
+			// Key masih berlaku dan tidak bisa diambil alih
+			mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+				WillReturnRows(sqlmock.NewRows([]string{"bool"}))
+			mock.ExpectQuery(`SELECT fingerprint, status_code, response_headers, response_body`).
+				WithArgs("user:5", "key-1").
+				WillReturnRows(tt.rows)
+
+			got, err := Begin(context.Background(), "user:5", "key-1", fp)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Begin error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("Begin = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.StatusCode != tt.want.StatusCode || string(got.Body) != string(tt.want.Body) ||
+				got.Header.Get("Location") != tt.want.Header.Get("Location") ||
+				got.Header.Get("Content-Type") != tt.want.Header.Get("Content-Type") {
+				t.Errorf("Begin = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompleteStoresHeaders(t *testing.T) {
+	mock := newMockDB(t)
+	mock.ExpectExec(`UPDATE idempotency_keys\s+SET status_code = \$3, response_headers = \$4, response_body = \$5`).
+		WithArgs("user:5", "key-1", 201, `{"Location":["/api/bookings/7"]}`, []byte(`{"id":7}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := Complete(context.Background(), "user:5", "key-1", Response{
+		StatusCode: 201,
+		Header:     http.Header{"Location": {"/api/bookings/7"}},
+		Body:       []byte(`{"id":7}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint("POST", "/api/bookings", []byte(`{"court_id":1}`))
+	if base != Fingerprint("POST", "/api/bookings", []byte(`{"court_id":1}`)) {
+		t.Error("same request has a different fingerprint")
+	}
+	for _, other := range []string{
+		Fingerprint("POST", "/api/bookings", []byte(`{"court_id":2}`)),
+		Fingerprint("POST", "/api/admin/bookings/1/payments", []byte(`{"court_id":1}`)),
+		Fingerprint("PUT", "/api/bookings", []byte(`{"court_id":1}`)),
+	} {
+		if other == base {
+			t.Error("different request has the same fingerprint")
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package kvstore berisi bagian store yang dipakai bersama oleh limiter
+// (loginguard dan ratelimit): pemilihan backend dan map in-memory yang
+// membersihkan entry lama.
+package kvstore
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/config"
+)
+
+// Nama backend di konfigurasi
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// Select memilih store dari nama backend: postgres memakai config.DB supaya
+// state dibagi semua instance, selain itu (default) memory
+func Select[S any](backend string, memory func() S, postgres func(*sql.DB) S) S {
+	if backend == BackendPostgres {
+		return postgres(config.DB)
+	}
+	return memory()
+}
+
+// Map adalah map dengan mutex untuk state per key, hanya cocok untuk satu instance.
+// Entry yang tidak diubah selama ttl dihapus (dicek paling sering sekali per ttl).
+type Map[V any] struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]entry[V]
+	lastSweep time.Time
+}
+
+type entry[V any] struct {
+	value   V
+	updated time.Time
+}
+
+// NewMap membuat Map kosong
+func NewMap[V any](ttl time.Duration) *Map[V] {
+	return &Map[V]{ttl: ttl, entries: map[string]entry[V]{}, lastSweep: time.Now()}
+}
+
+// Get mengembalikan nilai key, zero value jika belum ada
+func (m *Map[V]) Get(key string) V {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.entries[key].value
+}
+
+// Update membaca dan mengubah nilai key secara atomic. fn menerima nilai saat ini
+// (zero value jika belum ada) dan mengembalikan nilai baru serta apakah disimpan.
+func (m *Map[V]) Update(key string, now time.Time, fn func(V) (V, bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if value, store := fn(m.entries[key].value); store {
+		m.entries[key] = entry[V]{value: value, updated: now}
+	}
+	m.sweep(now)
+}
+
+// Delete menghapus key
+func (m *Map[V]) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+// sweep menghapus entry lama supaya memory tidak terus bertambah.
+// Harus dipanggil dengan mu terkunci.
+func (m *Map[V]) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < m.ttl {
+		return
+	}
+	for key, e := range m.entries {
+		if now.Sub(e.updated) > m.ttl {
+			delete(m.entries, key)
+		}
+	}
+	m.lastSweep = now
+}
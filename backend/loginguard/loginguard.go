@@ -0,0 +1,140 @@
+// Package loginguard membatasi percobaan login yang gagal per akun dan per IP:
+// setelah beberapa kali gagal ada jeda yang makin lama, lalu dikunci sementara.
+package loginguard
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/kvstore"
+)
+
+// State adalah jumlah gagal login berturut-turut untuk satu key
+type State struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// Store menyimpan state gagal login. MemoryStore untuk satu instance,
+// PostgresStore jika aplikasi berjalan di beberapa instance.
+type Store interface {
+	Get(ctx context.Context, key string) (State, error)
+	// RecordFailure menambah jumlah gagal, dimulai dari 1 lagi jika gagal
+	// terakhir sudah lebih lama dari window
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (State, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// Policy mengatur kapan jeda dan kunci berlaku
+type Policy struct {
+	FreeAttempts int           // gagal tanpa jeda
+	MaxFailures  int           // jumlah gagal sampai dikunci
+	BaseDelay    time.Duration // jeda setelah FreeAttempts, lalu dikali dua setiap gagal
+	MaxDelay     time.Duration
+	Window       time.Duration // gagal lebih lama dari ini tidak dihitung lagi
+	LockDuration time.Duration
+}
+
+// Policy default: akun lebih ketat dari IP karena satu IP bisa dipakai banyak user (NAT)
+var (
+	AccountPolicy = Policy{FreeAttempts: 3, MaxFailures: 10, BaseDelay: time.Second, MaxDelay: 30 * time.Second, Window: 15 * time.Minute, LockDuration: 15 * time.Minute}
+	IPPolicy      = Policy{FreeAttempts: 10, MaxFailures: 50, BaseDelay: time.Second, MaxDelay: 30 * time.Second, Window: 15 * time.Minute, LockDuration: 15 * time.Minute}
+)
+
+// retryAt menghitung kapan key boleh mencoba login lagi
+func (p Policy) retryAt(s State) time.Time {
+	if s.Failures >= p.MaxFailures {
+		return s.LastFailure.Add(p.LockDuration)
+	}
+	if s.Failures < p.FreeAttempts {
+		return time.Time{}
+	}
+	delay := p.BaseDelay << (s.Failures - p.FreeAttempts)
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	return s.LastFailure.Add(delay)
+}
+
+// Decision adalah hasil pengecekan sebelum password diverifikasi
+type Decision struct {
+	Allowed    bool
+	Locked     bool // true jika dikunci sementara, false jika hanya jeda
+	RetryAfter time.Duration
+}
+
+// Default adalah store yang dipakai aplikasi, diatur oleh Setup
+var Default Store = NewMemoryStore()
+
+// Setup memilih store dari backend ("memory" atau "postgres", default "memory")
+func Setup(backend string) {
+	Default = kvstore.Select(backend,
+		func() Store { return NewMemoryStore() },
+		func(db *sql.DB) Store { return NewPostgresStore(db) },
+	)
+}
+
+// AccountKey dan IPKey membuat key store untuk akun dan IP
+func AccountKey(username string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check mengecek apakah login untuk username dari IP tersebut boleh dicoba sekarang
+func Check(ctx context.Context, username, ip string) (Decision, error) {
+	now := time.Now()
+	decision := Decision{Allowed: true}
+
+	checks := []struct {
+		key    string
+		policy Policy
+	}{
+		{AccountKey(username), AccountPolicy},
+		{IPKey(ip), IPPolicy},
+	}
+	for _, check := range checks {
+		state, err := Default.Get(ctx, check.key)
+		if err != nil {
+			return Decision{}, err
+		}
+		if now.Sub(state.LastFailure) > check.policy.Window && state.Failures < check.policy.MaxFailures {
+			continue
+		}
+
+		retryAt := check.policy.retryAt(state)
+		if wait := retryAt.Sub(now); wait > 0 {
+			decision.Allowed = false
+			decision.Locked = decision.Locked || state.Failures >= check.policy.MaxFailures
+			if wait > decision.RetryAfter {
+				decision.RetryAfter = wait
+			}
+		}
+	}
+	return decision, nil
+}
+
+// RecordFailure mencatat login gagal untuk akun dan IP
+func RecordFailure(ctx context.Context, username, ip string) error {
+	now := time.Now()
+	if _, err := Default.RecordFailure(ctx, AccountKey(username), now, AccountPolicy.Window); err != nil {
+		return err
+	}
+	_, err := Default.RecordFailure(ctx, IPKey(ip), now, IPPolicy.Window)
+	return err
+}
+
+// RecordSuccess menghapus hitungan gagal akun setelah login berhasil.
+// Hitungan IP tidak dihapus supaya penyerang tidak bisa me-reset dengan akun miliknya sendiri.
+func RecordSuccess(ctx context.Context, username string) error {
+	return Default.Reset(ctx, AccountKey(username))
+}
+
+// Unlock membuka kunci akun, dipakai admin
+func Unlock(ctx context.Context, username string) error {
+	return Default.Reset(ctx, AccountKey(username))
+}
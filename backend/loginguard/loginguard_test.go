@@ -0,0 +1,160 @@
+package loginguard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPolicyRetryAt(t *testing.T) {
+	last := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		policy   Policy
+		failures int
+		want     time.Duration // 0 = boleh langsung mencoba lagi
+	}{
+		{"account first failure", AccountPolicy, 1, 0},
+		{"account last free attempt", AccountPolicy, 2, 0},
+		{"account first delay", AccountPolicy, 3, time.Second},
+		{"account delay doubles", AccountPolicy, 5, 4 * time.Second},
+		{"account delay capped", AccountPolicy, 9, 30 * time.Second},
+		{"account locked", AccountPolicy, 10, 15 * time.Minute},
+		{"account stays locked", AccountPolicy, 12, 15 * time.Minute},
+		{"ip free attempts", IPPolicy, 9, 0},
+		{"ip first delay", IPPolicy, 10, time.Second},
+		{"ip locked", IPPolicy, 50, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.retryAt(State{Failures: tt.failures, LastFailure: last})
+			if tt.want == 0 {
+				if !got.IsZero() {
+					t.Errorf("retryAt = %v, want no delay", got)
+				}
+				return
+			}
+			if wait := got.Sub(last); wait != tt.want {
+				t.Errorf("retryAt - last failure = %v, want %v", wait, tt.want)
+			}
+		})
+	}
+}
+
+// useMemoryStore memasang store kosong untuk satu test
+func useMemoryStore(t *testing.T) {
+	t.Helper()
+	prev := Default
+	Default = NewMemoryStore()
+	t.Cleanup(func() { Default = prev })
+}
+
+func recordFailures(t *testing.T, n int, username, ip string) {
+	t.Helper()
+	for range n {
+		if err := RecordFailure(context.Background(), username, ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckAccountThresholds(t *testing.T) {
+	tests := []struct {
+		failures   int
+		allowed    bool
+		locked     bool
+		maxRetryIn time.Duration
+	}{
+		{0, true, false, 0},
+		{AccountPolicy.FreeAttempts - 1, true, false, 0},
+		{AccountPolicy.FreeAttempts, false, false, AccountPolicy.BaseDelay},
+		{AccountPolicy.MaxFailures - 1, false, false, AccountPolicy.MaxDelay},
+		{AccountPolicy.MaxFailures, false, true, AccountPolicy.LockDuration},
+	}
+	for _, tt := range tests {
+		useMemoryStore(t)
+		recordFailures(t, tt.failures, "Budi", "203.0.113.7")
+
+		// Username tidak peka huruf besar/kecil, sama seperti login
+		d, err := Check(context.Background(), "budi", "198.51.100.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Allowed != tt.allowed || d.Locked != tt.locked {
+			t.Errorf("%d failures: allowed=%v locked=%v, want allowed=%v locked=%v",
+				tt.failures, d.Allowed, d.Locked, tt.allowed, tt.locked)
+		}
+		if d.RetryAfter < 0 || d.RetryAfter > tt.maxRetryIn {
+			t.Errorf("%d failures: RetryAfter = %v, want at most %v", tt.failures, d.RetryAfter, tt.maxRetryIn)
+		}
+		if !tt.allowed && d.RetryAfter == 0 {
+			t.Errorf("%d failures: RetryAfter = 0 for a blocked attempt", tt.failures)
+		}
+	}
+}
+
+func TestCheckIPThreshold(t *testing.T) {
+	useMemoryStore(t)
+	// Satu IP mencoba banyak akun berbeda, masing-masing akun hanya gagal sekali
+	for i := range IPPolicy.MaxFailures {
+		recordFailures(t, 1, fmt.Sprintf("user%d", i), "203.0.113.7")
+	}
+
+	d, err := Check(context.Background(), "someone-else", "203.0.113.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Allowed || !d.Locked {
+		t.Errorf("IP after %d failures: allowed=%v locked=%v, want locked", IPPolicy.MaxFailures, d.Allowed, d.Locked)
+	}
+
+	// Akun yang sama dari IP lain tidak terkena kunci IP
+	d, err = Check(context.Background(), "someone-else", "198.51.100.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Allowed {
+		t.Errorf("other IP blocked: %+v", d)
+	}
+}
+
+func TestRecordSuccessKeepsIPFailures(t *testing.T) {
+	useMemoryStore(t)
+	ctx := context.Background()
+	recordFailures(t, AccountPolicy.MaxFailures, "budi", "203.0.113.7")
+
+	if err := RecordSuccess(ctx, "budi"); err != nil {
+		t.Fatal(err)
+	}
+	account, err := Default.Get(ctx, AccountKey("budi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Failures != 0 {
+		t.Errorf("account failures after success = %d, want 0", account.Failures)
+	}
+	ip, err := Default.Get(ctx, IPKey("203.0.113.7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip.Failures != AccountPolicy.MaxFailures {
+		t.Errorf("ip failures after success = %d, want %d", ip.Failures, AccountPolicy.MaxFailures)
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	useMemoryStore(t)
+	recordFailures(t, AccountPolicy.MaxFailures, "budi", "203.0.113.7")
+
+	if err := Unlock(context.Background(), "BUDI"); err != nil {
+		t.Fatal(err)
+	}
+	d, err := Check(context.Background(), "budi", "198.51.100.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Allowed {
+		t.Errorf("unlocked account still blocked: %+v", d)
+	}
+}
@@ -0,0 +1,44 @@
+package loginguard
+
+import (
+	"context"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/kvstore"
+)
+
+// sweepInterval adalah lama entry tanpa gagal login baru sebelum dihapus
+const sweepInterval = time.Hour
+
+// MemoryStore menyimpan state di memory, hanya cocok untuk satu instance
+type MemoryStore struct {
+	entries *kvstore.Map[State]
+}
+
+// NewMemoryStore membuat MemoryStore kosong
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: kvstore.NewMap[State](sweepInterval)}
+}
+
+func (m *MemoryStore) Get(_ context.Context, key string) (State, error) {
+	return m.entries.Get(key), nil
+}
+
+func (m *MemoryStore) RecordFailure(_ context.Context, key string, now time.Time, window time.Duration) (State, error) {
+	var state State
+	m.entries.Update(key, now, func(s State) (State, bool) {
+		if now.Sub(s.LastFailure) > window {
+			s.Failures = 0
+		}
+		s.Failures++
+		s.LastFailure = now
+		state = s
+		return s, true
+	})
+	return state, nil
+}
+
+func (m *MemoryStore) Reset(_ context.Context, key string) error {
+	m.entries.Delete(key)
+	return nil
+}
@@ -0,0 +1,50 @@
+package loginguard
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PostgresStore menyimpan state di tabel login_throttle supaya dipakai bersama
+// oleh semua instance aplikasi
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore membuat PostgresStore dari koneksi database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (p *PostgresStore) Get(ctx context.Context, key string) (State, error) {
+	var state State
+	err := p.db.QueryRowContext(ctx,
+		"SELECT failures, last_failure_at FROM login_throttle WHERE key = $1", key,
+	).Scan(&state.Failures, &state.LastFailure)
+	if err == sql.ErrNoRows {
+		return State{}, nil
+	}
+	return state, err
+}
+
+func (p *PostgresStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (State, error) {
+	// Upsert atomic supaya percobaan paralel dari beberapa instance tetap terhitung semua
+	var state State
+	err := p.db.QueryRowContext(ctx, `
+		INSERT INTO login_throttle (key, failures, last_failure_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_throttle.last_failure_at < $2 - $3 * INTERVAL '1 second' THEN 1
+				ELSE login_throttle.failures + 1
+			END,
+			last_failure_at = $2
+		RETURNING failures, last_failure_at
+	`, key, now, int(window.Seconds())).Scan(&state.Failures, &state.LastFailure)
+	return state, err
+}
+
+func (p *PostgresStore) Reset(ctx context.Context, key string) error {
+	_, err := p.db.ExecContext(ctx, "DELETE FROM login_throttle WHERE key = $1", key)
+	return err
+}
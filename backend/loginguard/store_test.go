@@ -0,0 +1,127 @@
+package loginguard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMemoryStoreRecordFailure(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	window := 15 * time.Minute
+
+	steps := []struct {
+		at   time.Duration // sejak start
+		want int
+	}{
+		{0, 1},
+		{time.Minute, 2},
+		{10 * time.Minute, 3},
+		// gagal terakhir lebih lama dari window: hitungan mulai dari 1 lagi
+		{26 * time.Minute, 1},
+		{27 * time.Minute, 2},
+	}
+	for _, step := range steps {
+		now := start.Add(step.at)
+		state, err := store.RecordFailure(ctx, "account:budi", now, window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.Failures != step.want || !state.LastFailure.Equal(now) {
+			t.Errorf("at +%v: state = %+v, want %d failures at %v", step.at, state, step.want, now)
+		}
+	}
+
+	got, err := store.Get(ctx, "account:budi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Failures != 2 {
+		t.Errorf("Get failures = %d, want 2", got.Failures)
+	}
+	if other, _ := store.Get(ctx, "account:other"); other.Failures != 0 {
+		t.Errorf("unknown key failures = %d, want 0", other.Failures)
+	}
+
+	if err := store.Reset(ctx, "account:budi"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Get(ctx, "account:budi"); got.Failures != 0 {
+		t.Errorf("failures after reset = %d, want 0", got.Failures)
+	}
+}
+
+func newPostgresStore(t *testing.T) (*PostgresStore, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	return NewPostgresStore(db), mock
+}
+
+func TestPostgresStoreGet(t *testing.T) {
+	store, mock := newPostgresStore(t)
+	last := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT failures, last_failure_at FROM login_throttle WHERE key = \$1`).
+		WithArgs("account:budi").
+		WillReturnRows(sqlmock.NewRows([]string{"failures", "last_failure_at"}).AddRow(4, last))
+	mock.ExpectQuery(`FROM login_throttle`).WithArgs("account:other").
+		WillReturnRows(sqlmock.NewRows([]string{"failures", "last_failure_at"}))
+
+	got, err := store.Get(context.Background(), "account:budi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Failures != 4 || !got.LastFailure.Equal(last) {
+		t.Errorf("Get = %+v, want 4 failures at %v", got, last)
+	}
+
+	// Key yang belum pernah gagal bukan error
+	got, err = store.Get(context.Background(), "account:other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != (State{}) {
+		t.Errorf("Get unknown key = %+v, want zero state", got)
+	}
+}
+
+func TestPostgresStoreRecordFailure(t *testing.T) {
+	store, mock := newPostgresStore(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Satu upsert atomic dengan window dalam detik
+	mock.ExpectQuery(`INSERT INTO login_throttle .* ON CONFLICT \(key\) DO UPDATE`).
+		WithArgs("ip:203.0.113.7", now, 900).
+		WillReturnRows(sqlmock.NewRows([]string{"failures", "last_failure_at"}).AddRow(3, now))
+
+	got, err := store.RecordFailure(context.Background(), "ip:203.0.113.7", now, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Failures != 3 || !got.LastFailure.Equal(now) {
+		t.Errorf("RecordFailure = %+v, want 3 failures at %v", got, now)
+	}
+}
+
+func TestPostgresStoreReset(t *testing.T) {
+	store, mock := newPostgresStore(t)
+	mock.ExpectExec(`DELETE FROM login_throttle WHERE key = \$1`).WithArgs("account:budi").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := store.Reset(context.Background(), "account:budi"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"net/smtp"
+	"strings"
+)
+
+// Message adalah email yang akan dikirim
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirim email. Implementasi bisa diganti, misalnya LogMailer untuk lokal.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Default adalah mailer yang dipakai aplikasi, diatur oleh Setup
+var Default Mailer = LogMailer{}
+
+// Setup memilih mailer dari driver ("smtp" atau "log", default "log").
+// smtp hanya dipakai jika driver "smtp".
+func Setup(driver string, smtp SMTPMailer) {
+	switch driver {
+	case "smtp":
+		Default = smtp
+	default:
+		Default = LogMailer{}
+	}
+}
+
+// Send mengirim email lewat mailer default
+func Send(msg Message) error {
+	return Default.Send(msg)
+}
+
+// LogMailer tidak mengirim email, hanya menulis isinya ke log.
+// Dipakai untuk development lokal.
+type LogMailer struct{}
+
+// Send menulis email ke log
+func (LogMailer) Send(msg Message) error {
+	slog.Info("mail not sent (log mailer)", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// SMTPMailer mengirim email lewat server SMTP
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send mengirim email plain text lewat SMTP
+func (m SMTPMailer) Send(msg Message) error {
+	if m.Host == "" {
+		return fmt.Errorf("mailer: SMTP_HOST is not set")
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + msg.To,
+		"Subject: " + msg.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		msg.Body,
+	}, "\r\n")
+
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{msg.To}, []byte(body))
+}
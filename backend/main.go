@@ -2,18 +2,29 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
-	"time"
+	_ "time/tzdata" // timezone venue tetap bisa dibaca di image tanpa tzdata
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/buildinfo"
 	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/controllers"
+	"github.com/HenryKristofani/GoFutsal/logging"
+	"github.com/HenryKristofani/GoFutsal/loginguard"
+	"github.com/HenryKristofani/GoFutsal/mailer"
+	"github.com/HenryKristofani/GoFutsal/purge"
+	"github.com/HenryKristofani/GoFutsal/ratelimit"
 	"github.com/HenryKristofani/GoFutsal/routes"
+	"github.com/HenryKristofani/GoFutsal/tracing"
+	"github.com/HenryKristofani/GoFutsal/validation"
+	"github.com/HenryKristofani/GoFutsal/venuetime"
 
 	// 👇 Swagger dependencies
 	_ "github.com/HenryKristofani/GoFutsal/docs"
@@ -39,43 +50,98 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
-// @description Type "Bearer" followed by a space and JWT token.
+// @description Type "Bearer" followed by a space and JWT token or API key (gfk_...). API keys may also be sent in the X-API-Key header.
 func main() {
-	// Load .env
-	godotenv.Load()
+	// Konfigurasi dari env, .env dan YAML opsional (CONFIG_FILE), berhenti jika tidak valid
+	cfg, err := config.Load()
+	if err != nil {
+		logging.Fatal("failed to load configuration", err)
+	}
+
+	// Logger JSON, dipasang paling awal supaya semua log memakai format yang sama
+	logging.Setup(cfg.Log.Level, cfg.Log.Format)
+
+	// Tracing OpenTelemetry (otlp, stdout atau none)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.ServiceName)
+	if err != nil {
+		logging.Fatal("failed to set up tracing", err)
+	}
+
+	// Daftarkan validator custom untuk request body
+	if err := validation.Register(); err != nil {
+		logging.Fatal("failed to register validators", err)
+	}
+
+	// Kunci untuk sign dan verifikasi JWT
+	if err := auth.LoadKeys(cfg.JWT.KeysDir, cfg.JWT.ActiveKID); err != nil {
+		logging.Fatal("failed to load JWT keys", err)
+	}
+
+	// Timezone default venue
+	if err := venuetime.SetDefault(cfg.Venue.Timezone); err != nil {
+		logging.Fatal("failed to load venue timezone", err)
+	}
+
+	// Pilih mailer (log untuk lokal, smtp untuk production)
+	mailer.Setup(cfg.Mail.Driver, mailer.SMTPMailer{
+		Host:     cfg.Mail.SMTPHost,
+		Port:     strconv.Itoa(cfg.Mail.SMTPPort),
+		Username: cfg.Mail.SMTPUsername,
+		Password: cfg.Mail.SMTPPassword,
+		From:     cfg.Mail.From,
+	})
+	controllers.SetAppBaseURL(cfg.Server.BaseURL)
 
 	// Connect ke database
-	config.ConnectDB()
+	if err := config.ConnectDB(cfg.Database); err != nil {
+		logging.Fatal("failed to connect to database", err)
+	}
 
 	// Run database migrations
-	config.CheckAndRunMigrations()
+	if err := config.CheckAndRunMigrations(); err != nil {
+		logging.Fatal("database migration failed", err)
+	}
+
+	// Limiter percobaan login (memory untuk satu instance, postgres untuk cluster)
+	loginguard.Setup(cfg.LoginGuard.Backend)
 
-	// Inisialisasi Gin
-	r := gin.Default()
+	// Store rate limiter per route (memory untuk satu instance, postgres untuk cluster)
+	ratelimit.Setup(cfg.RateLimit.Backend)
+
+	// Job background: purge permanen data soft delete yang lewat masa retensi
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	purge.Start(jobsCtx, cfg.Workers.SoftDeleteRetentionDays, cfg.Workers.PurgeInterval)
+
+	// Inisialisasi Gin tanpa logger bawaan, access log dan recovery dipasang di routes
+	gin.DebugPrintRouteFunc = func(method, path, handler string, _ int) {
+		slog.Debug("route registered", "method", method, "path", path, "handler", handler)
+	}
+	r := gin.New()
+
+	// IP client (rate limit, login guard) hanya diambil dari X-Forwarded-For jika request
+	// datang dari proxy yang dipercaya, supaya client tidak bisa memalsukan IP-nya
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logging.Fatal("invalid trusted proxies", err)
+	}
 
 	// Setup semua route dari folder routes/
-	routes.SetupRoutes(r)
+	routes.SetupRoutes(r, cfg)
 
 	// ✅ Tambahkan route Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Setup graceful shutdown
-	port := os.Getenv("APP_PORT")
-	if port == "" {
-		port = "8080"
-	}
-
 	srv := &http.Server{
-		Addr:    ":" + port,
+		Addr:    ":" + strconv.Itoa(cfg.Server.Port),
 		Handler: r,
 	}
 
 	// Jalankan server di goroutine
 	go func() {
-		fmt.Printf("🚀 GoFutsal server running on port %s\n", port)
-		fmt.Println("📝 Tekan Ctrl+C untuk shutdown gracefully")
+		slog.Info("server started", "port", cfg.Server.Port, "version", buildinfo.Version, "commit", buildinfo.Commit)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("❌ Server gagal start: %s\n", err)
+			logging.Fatal("server failed to start", err)
 		}
 	}()
 
@@ -83,15 +149,19 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	fmt.Println("🛑 Shutdown server...")
+	slog.Info("shutting down server")
+	stopJobs()
 
-	// Graceful shutdown dengan timeout 5 detik
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Graceful shutdown dengan timeout SHUTDOWN_TIMEOUT (default 5 detik)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		fmt.Printf("❌ Server forced shutdown: %s\n", err)
+		slog.Error("server forced shutdown", "error", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("tracing shutdown failed", "error", err)
 	}
 
-	fmt.Println("✅ Server berhasil shutdown")
+	slog.Info("server stopped")
 }
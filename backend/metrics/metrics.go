@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metric Prometheus yang di-expose di /metrics. Metric HTTP diisi oleh
+// middleware.Metrics, metric bisnis diisi controller saat datanya berubah.
+
+const namespace = "gofutsal"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Jumlah HTTP request per route, method dan status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency HTTP request per route dan method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Jumlah HTTP request yang sedang diproses.",
+	})
+
+	bookingsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bookings_created_total",
+		Help:      "Jumlah booking yang dibuat.",
+	})
+
+	bookingsCancelled = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bookings_cancelled_total",
+		Help:      "Jumlah booking yang dibatalkan (dihapus).",
+	})
+
+	revenue = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revenue_total",
+		Help:      "Total pembayaran yang diterima per metode pembayaran.",
+	}, []string{"method"})
+
+	payments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payments_total",
+		Help:      "Jumlah pembayaran yang diterima per metode pembayaran.",
+	}, []string{"method"})
+
+	loginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Jumlah login gagal per alasan.",
+	}, []string{"reason"})
+)
+
+// RegisterDB menambahkan statistik connection pool (sql.DB.Stats) ke /metrics
+func RegisterDB(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "gofutsal"))
+}
+
+// Handler mengembalikan handler HTTP untuk endpoint /metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RequestStarted dan RequestFinished dipanggil middleware di awal dan akhir request.
+// route adalah pola route Gin (misalnya /api/bookings/:id), bukan path asli,
+// supaya jumlah label tetap kecil.
+func RequestStarted() {
+	httpInFlight.Inc()
+}
+
+func RequestFinished(method, route string, status int, elapsed time.Duration) {
+	httpInFlight.Dec()
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// BookingCreated mencatat satu booking baru
+func BookingCreated() {
+	bookingsCreated.Inc()
+}
+
+// BookingsCancelled mencatat booking yang dibatalkan
+func BookingsCancelled(n int) {
+	bookingsCancelled.Add(float64(n))
+}
+
+// PaymentReceived mencatat pembayaran dan menambah revenue
+func PaymentReceived(method string, amount int) {
+	payments.WithLabelValues(method).Inc()
+	revenue.WithLabelValues(method).Add(float64(amount))
+}
+
+// LoginFailed mencatat login gagal dengan alasan (models.Login*)
+func LoginFailed(reason string) {
+	loginFailures.WithLabelValues(reason).Inc()
+}
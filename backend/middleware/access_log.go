@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog middleware menulis satu log per request berisi status, latency dan pelaku.
+// Dipasang setelah RequestID supaya setiap baris log membawa request_id.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if userID := c.GetInt("user_id"); userID > 0 {
+			attrs = append(attrs, slog.Int("user_id", userID))
+		}
+		if key := apikey.FromContext(c); key != nil {
+			attrs = append(attrs, slog.Int("api_key_id", key.ID))
+		}
+
+		slog.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+	}
+}
@@ -1,51 +1,69 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/HenryKristofani/GoFutsal/apperror"
 	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/rbac"
 	"github.com/gin-gonic/gin"
 )
 
-// AuthRequired middleware untuk validasi JWT token
+// AuthRequired middleware untuk validasi JWT token atau API key.
+// API key bisa dikirim lewat header X-API-Key atau Authorization: Bearer gfk_...
 // @Summary JWT Authentication Middleware
-// @Description Middleware untuk memvalidasi JWT token di header Authorization
+// @Description Middleware untuk memvalidasi JWT token atau API key di header Authorization
 // @Security BearerAuth
 func AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			authenticateAPIKey(c, key)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Authorization header required",
-			})
-			c.Abort()
+			abortWithError(c, apperror.Unauthorized("Authorization header required"))
 			return
 		}
 
 		// Check Bearer format
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Invalid authorization format. Use 'Bearer <token>'",
-			})
-			c.Abort()
+			abortWithError(c, apperror.Unauthorized("Invalid authorization format. Use 'Bearer <token>'"))
 			return
 		}
 
 		// Extract token
 		token := strings.TrimPrefix(authHeader, "Bearer ")
+		if apikey.IsAPIKey(token) {
+			authenticateAPIKey(c, token)
+			return
+		}
 
 		// Validate token
 		claims, err := auth.ValidateToken(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Invalid or expired token: " + err.Error(),
-			})
-			c.Abort()
+			abortWithError(c, apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid or expired token"))
+			return
+		}
+
+		// Access token selalu terikat ke session yang bisa dicabut (logout perangkat)
+		if claims.SessionID == "" {
+			abortWithError(c, apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid or expired token"))
+			return
+		}
+		active, err := auth.TouchSession(c.Request.Context(), claims.SessionID, claims.UserID, c.ClientIP())
+		if err != nil {
+			abortWithError(c, apperror.Internal(err))
+			return
+		}
+		if !active {
+			abortWithError(c, apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Session has been revoked or expired"))
 			return
 		}
 
@@ -54,11 +72,50 @@ func AuthRequired() gin.HandlerFunc {
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
 
 		c.Next()
 	}
 }
 
+// authenticateAPIKey memvalidasi API key dan menerapkan rate limit per key.
+// Request dengan API key tidak punya user_id maupun role, permission diambil dari key.
+func authenticateAPIKey(c *gin.Context, raw string) {
+	key, err := apikey.Authenticate(c.Request.Context(), raw, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, apikey.ErrInvalid) {
+			abortWithError(c, apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid, revoked or expired API key"))
+			return
+		}
+		abortWithError(c, apperror.Internal(err))
+		return
+	}
+
+	// Limit per menit milik key, dibagi semua instance jika RATE_LIMIT_BACKEND=postgres
+	if !takeToken(c, apiKeyRateLimit(key), strconv.Itoa(key.ID)) {
+		return
+	}
+
+	c.Set(apikey.ContextKey, key)
+	c.Set("username", "apikey:"+key.Name)
+	c.Next()
+}
+
+// UserRequired middleware untuk route yang hanya masuk akal untuk akun user
+// (profil, 2FA, booking milik sendiri) sehingga tidak bisa dipakai dengan API key
+// @Summary User Account Middleware
+// @Description Middleware untuk menolak request yang memakai API key
+// @Security BearerAuth
+func UserRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apikey.FromContext(c) != nil {
+			abortWithError(c, apperror.Forbidden("This endpoint requires a user account, not an API key"))
+			return
+		}
+		c.Next()
+	}
+}
+
 // AdminRequired middleware untuk memastikan user adalah admin
 // @Summary Admin Role Middleware
 // @Description Middleware untuk memvalidasi bahwa user memiliki role admin
@@ -67,33 +124,55 @@ func AdminRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
 		if !exists || role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"success": false,
-				"message": "Admin access required",
-			})
-			c.Abort()
+			abortWithError(c, apperror.Forbidden("Admin access required"))
 			return
 		}
 		c.Next()
 	}
 }
 
-// CORS middleware untuk menangani cross-origin requests
-// @Summary CORS Middleware
-// @Description Middleware untuk menangani CORS policy
-func CORS() gin.HandlerFunc {
+// RequirePermission middleware untuk memastikan role user (atau API key) memiliki permission tertentu,
+// contoh: RequirePermission("bookings:checkin")
+// @Summary Permission Middleware
+// @Description Middleware untuk memvalidasi permission role user dari database
+// @Security BearerAuth
+func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		c.Header("Access-Control-Expose-Headers", "Content-Length")
-		c.Header("Access-Control-Allow-Credentials", "true")
+		allowed, err := rbac.ContextHasPermission(c, permission)
+		if err != nil {
+			abortWithError(c, apperror.Internal(err))
+			return
+		}
+		if !allowed {
+			abortWithError(c, apperror.Forbidden("Missing permission: "+permission))
+			return
+		}
+		c.Next()
+	}
+}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+// SelfOrPermission middleware untuk resource milik user: lolos jika parameter path
+// adalah ID user yang login, selain itu role harus memiliki permission tertentu,
+// contoh: SelfOrPermission("id", "users:manage")
+// @Summary Self or Permission Middleware
+// @Description Middleware untuk membatasi akses data user ke pemilik akun atau role dengan permission
+// @Security BearerAuth
+func SelfOrPermission(param, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apikey.FromContext(c) == nil && c.Param(param) == strconv.Itoa(c.GetInt("user_id")) {
+			c.Next()
 			return
 		}
 
+		allowed, err := rbac.ContextHasPermission(c, permission)
+		if err != nil {
+			abortWithError(c, apperror.Internal(err))
+			return
+		}
+		if !allowed {
+			abortWithError(c, apperror.Forbidden("You can only access your own account"))
+			return
+		}
 		c.Next()
 	}
 }
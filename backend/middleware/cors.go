@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Method dan header yang boleh dipakai request cross-origin
+var (
+	corsAllowedMethods = strings.Join([]string{
+		http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}, ", ")
+	corsAllowedHeaders = strings.Join([]string{
+		"Authorization", "Content-Type", "X-API-Key", "Idempotency-Key", "X-Request-ID", "traceparent", "tracestate",
+	}, ", ")
+	corsExposedHeaders = strings.Join([]string{
+		"Content-Length", "X-Request-ID", "Retry-After", "Idempotent-Replayed",
+		"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+	}, ", ")
+)
+
+// CORS middleware mengizinkan request browser dari origin yang dikonfigurasi.
+// Origin yang tidak diizinkan tidak mendapat header CORS (browser memblokir response),
+// dan preflight-nya ditolak dengan 403. cfg sudah divalidasi saat config.Load.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	var patterns []config.OriginPattern
+	for _, o := range cfg.AllowedOrigins {
+		if p, err := config.ParseOriginPattern(o); err == nil {
+			patterns = append(patterns, p)
+		}
+	}
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		// Response berbeda per origin, cache di antara (CDN/proxy) harus membedakannya
+		c.Writer.Header().Add("Vary", "Origin")
+
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		allowed, anyOrigin := false, false
+		for _, p := range patterns {
+			if p.Match(origin) {
+				allowed, anyOrigin = true, p.Any
+				break
+			}
+		}
+		if !allowed {
+			if preflight {
+				abortWithError(c, apperror.Forbidden("Origin not allowed"))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if anyOrigin && !cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", corsAllowedMethods)
+			c.Header("Access-Control-Allow-Headers", corsAllowedHeaders)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Header("Access-Control-Expose-Headers", corsExposedHeaders)
+		c.Next()
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func newCORSRouter(cfg config.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler(), CORS(cfg))
+	r.GET("/api/courts", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return r
+}
+
+func corsRequest(r *gin.Engine, method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/courts", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSPreflight(t *testing.T) {
+	r := newCORSRouter(config.CORSConfig{
+		AllowedOrigins: []string{"https://gofutsal.id", "https://*.gofutsal.id"},
+		MaxAge:         10 * time.Minute,
+	})
+
+	w := corsRequest(r, http.MethodOptions, "https://admin.gofutsal.id", map[string]string{
+		"Access-Control-Request-Method":  http.MethodPatch,
+		"Access-Control-Request-Headers": "Idempotency-Key, X-Request-ID",
+	})
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", w.Code)
+	}
+	h := w.Header()
+	if got := h.Get("Access-Control-Allow-Origin"); got != "https://admin.gofutsal.id" {
+		t.Errorf("Access-Control-Allow-Origin = %q", got)
+	}
+	if got := h.Get("Access-Control-Allow-Methods"); !strings.Contains(got, http.MethodPatch) {
+		t.Errorf("Access-Control-Allow-Methods = %q, want PATCH", got)
+	}
+	for _, header := range []string{"Authorization", "Idempotency-Key", "X-Request-ID"} {
+		if got := h.Get("Access-Control-Allow-Headers"); !strings.Contains(got, header) {
+			t.Errorf("Access-Control-Allow-Headers = %q, want %s", got, header)
+		}
+	}
+	if got := h.Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("Access-Control-Max-Age = %q, want 600", got)
+	}
+	for _, v := range []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"} {
+		if !slices.Contains(h.Values("Vary"), v) {
+			t.Errorf("Vary = %q, want %s", h.Values("Vary"), v)
+		}
+	}
+	if got := h.Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials = %q, want unset", got)
+	}
+}
+
+func TestCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.CORSConfig
+		method      string
+		origin      string
+		wantStatus  int
+		wantOrigin  string
+		credentials bool
+	}{
+		{
+			name:       "exact origin",
+			cfg:        config.CORSConfig{AllowedOrigins: []string{"https://gofutsal.id"}},
+			method:     http.MethodGet,
+			origin:     "https://gofutsal.id",
+			wantStatus: http.StatusOK,
+			wantOrigin: "https://gofutsal.id",
+		},
+		{
+			name:       "disallowed origin",
+			cfg:        config.CORSConfig{AllowedOrigins: []string{"https://*.gofutsal.id"}},
+			method:     http.MethodGet,
+			origin:     "https://evil-gofutsal.id",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "disallowed preflight",
+			cfg:        config.CORSConfig{AllowedOrigins: []string{"https://*.gofutsal.id"}},
+			method:     http.MethodOptions,
+			origin:     "https://gofutsal.id",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "no origin header",
+			cfg:        config.CORSConfig{AllowedOrigins: []string{"https://gofutsal.id"}},
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "any origin without credentials",
+			cfg:        config.CORSConfig{AllowedOrigins: []string{"*"}},
+			method:     http.MethodGet,
+			origin:     "https://other.example",
+			wantStatus: http.StatusOK,
+			wantOrigin: "*",
+		},
+		{
+			name:        "credentials echo the origin",
+			cfg:         config.CORSConfig{AllowedOrigins: []string{"https://gofutsal.id"}, AllowCredentials: true},
+			method:      http.MethodGet,
+			origin:      "https://gofutsal.id",
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://gofutsal.id",
+			credentials: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers map[string]string
+			if tt.method == http.MethodOptions {
+				headers = map[string]string{"Access-Control-Request-Method": http.MethodGet}
+			}
+			w := corsRequest(newCORSRouter(tt.cfg), tt.method, tt.origin, headers)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.credentials {
+				t.Errorf("Access-Control-Allow-Credentials = %v, want %v", got, tt.credentials)
+			}
+			if !slices.Contains(w.Header().Values("Vary"), "Origin") {
+				t.Errorf("Vary = %q, want Origin", w.Header().Values("Vary"))
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler middleware mengubah error yang dicatat handler lewat c.Error
+// menjadi response JSON dengan format apperror.Response
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperror.As(c.Errors.Last().Err)
+		requestID := c.GetString("request_id")
+
+		// Error server dicatat beserta penyebabnya, error client cukup di level debug
+		// karena status-nya sudah tercatat di access log
+		ctx := c.Request.Context()
+		switch {
+		case appErr.Status >= 500:
+			slog.ErrorContext(ctx, "request failed", "code", appErr.Code, "error", appErr)
+		case appErr.Cause != nil:
+			slog.WarnContext(ctx, "request rejected", "code", appErr.Code, "error", appErr)
+		default:
+			slog.DebugContext(ctx, "request rejected", "code", appErr.Code, "message", appErr.Message)
+		}
+
+		c.JSON(appErr.Status, appErr.Response(requestID))
+	}
+}
+
+// abortWithError mencatat error dan menghentikan handler berikutnya
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader adalah header yang dikirim client untuk request yang boleh diulang
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength sama dengan panjang kolom key di tabel idempotency_keys
+const maxIdempotencyKeyLength = 255
+
+// replayableHeader menentukan header response yang disimpan untuk replay. Header yang
+// berlaku per request (request ID, CORS untuk origin pemanggil) atau berisi rahasia
+// (cookie) tidak disimpan. name adalah key http.Header (sudah canonical).
+func replayableHeader(name string) bool {
+	switch name {
+	case "Content-Length", "Date", "Set-Cookie", "Vary", http.CanonicalHeaderKey(RequestIDHeader):
+		return false
+	}
+	return !strings.HasPrefix(name, "Access-Control-")
+}
+
+// responseRecorder menyalin body response supaya bisa disimpan untuk replay
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency middleware untuk request yang membuat data (booking, pembayaran).
+// Jika client mengirim header Idempotency-Key, response pertama disimpan dan
+// request ulang dengan key dan body yang sama mendapat response yang sama tanpa
+// diproses lagi (ditandai header Idempotent-Replayed). Key yang dipakai ulang dengan
+// body lain ditolak 422, dan request ulang selagi yang pertama masih diproses ditolak 409.
+// Request yang gagal (error atau 5xx) tidak disimpan sehingga boleh dicoba lagi.
+// Header response pertama (Location, RateLimit-*, ...) ikut di-replay, kecuali yang sudah
+// di-set ulang untuk request ini seperti RateLimit-* terbaru dan CORS.
+// Body dibaca utuh untuk fingerprint, maksimal maxBodyBytes (lebih besar ditolak 413).
+// Key berlaku per user atau per API key, jadi harus dipasang setelah AuthRequired.
+func Idempotency(maxBodyBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, apperror.BadRequest("Idempotency-Key must be at most 255 characters"))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxBodyBytes)))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			abortWithError(c, apperror.New(http.StatusRequestEntityTooLarge, apperror.CodeBadRequest,
+				fmt.Sprintf("Request body must be at most %d bytes", maxBodyBytes)))
+			return
+		}
+		if err != nil {
+			abortWithError(c, apperror.BadRequest("Failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scope := idempotencyScope(c)
+		fingerprint := idempotency.Fingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		stored, err := idempotency.Begin(ctx, scope, key, fingerprint)
+		switch {
+		case errors.Is(err, idempotency.ErrMismatch):
+			abortWithError(c, apperror.IdempotencyKeyReused())
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			c.Header("Retry-After", "1")
+			abortWithError(c, apperror.Conflict("A request with this Idempotency-Key is still being processed"))
+			return
+		case err != nil:
+			abortWithError(c, apperror.Internal(err))
+			return
+		case stored != nil:
+			header := c.Writer.Header()
+			for name, values := range stored.Header {
+				if _, ok := header[name]; !ok {
+					header[name] = values
+				}
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(stored.StatusCode, header.Get("Content-Type"), stored.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Response disimpan walaupun request sudah timeout atau client sudah putus
+		saveCtx := context.WithoutCancel(ctx)
+		status := recorder.Status()
+		if len(c.Errors) > 0 || !recorder.Written() || status >= http.StatusInternalServerError {
+			if err := idempotency.Release(saveCtx, scope, key); err != nil {
+				slog.ErrorContext(ctx, "idempotency key release failed", "error", err)
+			}
+			return
+		}
+
+		header := http.Header{}
+		for name, values := range recorder.Header() {
+			if replayableHeader(name) {
+				header[name] = values
+			}
+		}
+		err = idempotency.Complete(saveCtx, scope, key, idempotency.Response{
+			StatusCode: status,
+			Header:     header,
+			Body:       recorder.body.Bytes(),
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "idempotency response save failed", "status", status, "error", err)
+		}
+	}
+}
+
+// idempotencyScope memisahkan key antar client supaya key milik user lain tidak bisa di-replay
+func idempotencyScope(c *gin.Context) string {
+	if key := apikey.FromContext(c); key != nil {
+		return "apikey:" + strconv.Itoa(key.ID)
+	}
+	return "user:" + strconv.Itoa(c.GetInt("user_id"))
+}
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+const bookingBody = `{"court_id":1}`
+
+// newIdempotencyRouter membuat router POST /api/bookings untuk user 5 dengan database sqlmock.
+// Handler membuat booking 7 dan menghitung berapa kali dipanggil. Sweep key kadaluarsa
+// (paling sering sekali per jam) tidak di-expect; jika jalan, errornya hanya di-log.
+func newIdempotencyRouter(t *testing.T, handler gin.HandlerFunc) (*gin.Engine, sqlmock.Sqlmock, *int) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		config.DB = prev
+		db.Close()
+	})
+
+	if handler == nil {
+		handler = func(c *gin.Context) {
+			c.Header("Location", "/api/bookings/7")
+			c.JSON(http.StatusCreated, gin.H{"id": 7})
+		}
+	}
+	calls := new(int)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID(), ErrorHandler())
+	r.POST("/api/bookings",
+		func(c *gin.Context) {
+			c.Set("user_id", 5)
+			c.Header("RateLimit-Remaining", "41")
+			c.Next()
+		},
+		Idempotency(64),
+		func(c *gin.Context) {
+			*calls++
+			handler(c)
+		},
+	)
+	return r, mock, calls
+}
+
+func idempotentRequest(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/bookings", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func expectBeginConflict(mock sqlmock.Sqlmock, fingerprint string, status any, headers, body string) {
+	mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+		WillReturnRows(sqlmock.NewRows([]string{"bool"}))
+	mock.ExpectQuery(`SELECT fingerprint, status_code, response_headers, response_body`).
+		WithArgs("user:5", "key-1").
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status_code", "response_headers", "response_body"}).
+			AddRow(fingerprint, status, []byte(headers), []byte(body)))
+}
+
+// storedHeaders cocok dengan JSON header yang disimpan Complete
+type storedHeaders func(http.Header) bool
+
+func (f storedHeaders) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	var h http.Header
+	return ok && json.Unmarshal([]byte(s), &h) == nil && f(h)
+}
+
+func TestIdempotencyStoresFirstResponse(t *testing.T) {
+	r, mock, calls := newIdempotencyRouter(t, nil)
+
+	// Key baru atau kadaluarsa (diambil alih oleh upsert): request diproses lalu response disimpan
+	mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+		WithArgs("user:5", "key-1", idempotency.Fingerprint(http.MethodPost, "/api/bookings", []byte(bookingBody)),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"bool"}).AddRow(true))
+	mock.ExpectExec(`UPDATE idempotency_keys`).
+		WithArgs("user:5", "key-1", http.StatusCreated, storedHeaders(func(h http.Header) bool {
+			return h.Get("Location") == "/api/bookings/7" &&
+				h.Get("RateLimit-Remaining") == "41" &&
+				strings.HasPrefix(h.Get("Content-Type"), "application/json") &&
+				h.Get(RequestIDHeader) == ""
+		}), []byte(`{"id":7}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := idempotentRequest(r, bookingBody)
+	if w.Code != http.StatusCreated || *calls != 1 {
+		t.Fatalf("status = %d, handler calls = %d; want 201, 1", w.Code, *calls)
+	}
+	if w.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("first response is marked as replayed")
+	}
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	r, mock, calls := newIdempotencyRouter(t, nil)
+	fp := idempotency.Fingerprint(http.MethodPost, "/api/bookings", []byte(bookingBody))
+	expectBeginConflict(mock, fp, http.StatusCreated,
+		`{"Content-Type":["application/json; charset=utf-8"],"Location":["/api/bookings/7"],"RateLimit-Remaining":["42"]}`,
+		`{"id":7}`)
+
+	w := idempotentRequest(r, bookingBody)
+	if *calls != 0 {
+		t.Fatalf("handler called %d times on replay, want 0", *calls)
+	}
+	if w.Code != http.StatusCreated || w.Body.String() != `{"id":7}` {
+		t.Fatalf("replay = %d %s, want 201 {\"id\":7}", w.Code, w.Body)
+	}
+	want := map[string]string{
+		"Idempotent-Replayed": "true",
+		"Location":            "/api/bookings/7",
+		"Content-Type":        "application/json; charset=utf-8",
+		// Header yang di-set untuk request ini tidak ditimpa nilai lama
+		"RateLimit-Remaining": "41",
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if w.Header().Get(RequestIDHeader) == "" {
+		t.Error("replay has no request ID")
+	}
+}
+
+func TestIdempotencyRejectsReuse(t *testing.T) {
+	fp := idempotency.Fingerprint(http.MethodPost, "/api/bookings", []byte(bookingBody))
+	tests := []struct {
+		name       string
+		stored     string
+		status     any
+		wantStatus int
+	}{
+		{"different body", idempotency.Fingerprint(http.MethodPost, "/api/bookings", []byte(`{"court_id":2}`)), http.StatusCreated, http.StatusUnprocessableEntity},
+		{"still processing", fp, nil, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mock, calls := newIdempotencyRouter(t, nil)
+			expectBeginConflict(mock, tt.stored, tt.status, `{}`, ``)
+
+			w := idempotentRequest(r, bookingBody)
+			if w.Code != tt.wantStatus || *calls != 0 {
+				t.Errorf("status = %d, handler calls = %d; want %d, 0", w.Code, *calls, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIdempotencyReleasesFailedRequest(t *testing.T) {
+	r, mock, _ := newIdempotencyRouter(t, func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+	})
+	mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+		WillReturnRows(sqlmock.NewRows([]string{"bool"}).AddRow(true))
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE scope = \$1 AND key = \$2 AND completed_at IS NULL`).
+		WithArgs("user:5", "key-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if w := idempotentRequest(r, bookingBody); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+}
+
+func TestIdempotencyBodyLimit(t *testing.T) {
+	// Body lebih besar dari batas ditolak sebelum menyentuh database
+	r, _, calls := newIdempotencyRouter(t, nil)
+
+	w := idempotentRequest(r, `{"notes":"`+strings.Repeat("a", 64)+`"}`)
+	if w.Code != http.StatusRequestEntityTooLarge || *calls != 0 {
+		t.Errorf("status = %d, handler calls = %d; want 413, 0", w.Code, *calls)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics middleware mencatat jumlah dan latency request per route untuk /metrics.
+// Request ke path yang tidak terdaftar dikelompokkan ke route "unmatched".
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		metrics.RequestStarted()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.RequestFinished(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsToken middleware melindungi /metrics dengan bearer token (METRICS_TOKEN).
+// Jika token kosong, /metrics terbuka (misalnya hanya bisa diakses dari jaringan internal).
+func MetricsToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			abortWithError(c, apperror.Unauthorized("Invalid metrics token"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// MFAEnforced middleware untuk menolak user yang role-nya mewajibkan 2FA
+// tetapi belum mengaktifkan 2FA. Route profil tetap bisa dipakai untuk enroll.
+// Request dengan API key tidak dicek karena tidak terkait akun user.
+// @Summary MFA Enforcement Middleware
+// @Description Middleware untuk mewajibkan 2FA pada role yang diatur admin
+// @Security BearerAuth
+func MFAEnforced() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apikey.FromContext(c) != nil {
+			c.Next()
+			return
+		}
+
+		var missing bool
+		err := config.DB.QueryRowContext(c.Request.Context(), `
+			SELECT COALESCE(r.mfa_required, FALSE) AND u.totp_enabled_at IS NULL
+			FROM users u LEFT JOIN roles r ON r.name = u.role
+			WHERE u.id = $1
+		`, c.GetInt("user_id")).Scan(&missing)
+		if err != nil {
+			abortWithError(c, apperror.FromDB(err, "User not found"))
+			return
+		}
+		if missing {
+			abortWithError(c, apperror.New(http.StatusForbidden, apperror.CodeMFARequired, "Two-factor authentication must be enabled for your role"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKey menentukan identitas client yang dibatasi bersama oleh satu bucket.
+// String kosong berarti request tidak dibatasi oleh policy tersebut.
+type RateLimitKey func(c *gin.Context) string
+
+// RateLimitByIP membatasi per alamat IP, untuk route publik
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByUser membatasi per user yang login, atau per IP jika belum login.
+// Request dengan API key dilewati karena sudah dibatasi limit milik key di AuthRequired.
+func RateLimitByUser(c *gin.Context) string {
+	if apikey.FromContext(c) != nil {
+		return ""
+	}
+	if userID := c.GetInt("user_id"); userID > 0 {
+		return "user:" + strconv.Itoa(userID)
+	}
+	return RateLimitByIP(c)
+}
+
+// apiKeyRateLimit adalah policy dari limit per menit milik API key
+func apiKeyRateLimit(key *apikey.Key) ratelimit.Policy {
+	return ratelimit.Policy{Name: "apikey", Limit: key.RateLimit, Period: time.Minute}
+}
+
+// RateLimit middleware membatasi request dengan token bucket per policy dan key.
+// Setiap response membawa header RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset
+// dan RateLimit-Policy; request yang melewati batas dijawab 429 dengan Retry-After.
+// Jika store gagal, request tetap dilayani supaya gangguan database tidak memblokir semua traffic.
+func RateLimit(policy ratelimit.Policy, key RateLimitKey) gin.HandlerFunc {
+	if policy.Limit <= 0 || policy.Period <= 0 {
+		panic("ratelimit: policy " + policy.Name + " must have a positive limit and period")
+	}
+	return func(c *gin.Context) {
+		identity := key(c)
+		if identity == "" || takeToken(c, policy, identity) {
+			c.Next()
+		}
+	}
+}
+
+// takeToken mengambil satu token dan mengisi header RateLimit-*. Mengembalikan false
+// (request sudah di-abort dengan 429) jika bucket kosong.
+func takeToken(c *gin.Context, policy ratelimit.Policy, identity string) bool {
+	res, err := ratelimit.Take(c.Request.Context(), policy, identity)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "rate limit check failed", "policy", policy.Name, "error", err)
+		return true
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+	c.Header("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(int(policy.Period.Seconds())))
+
+	if !res.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+		abortWithError(c, apperror.TooManyRequests("Too many requests, please slow down"))
+		return false
+	}
+	return true
+}
+
+// ceilSeconds membulatkan durasi ke atas dalam detik untuk header
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
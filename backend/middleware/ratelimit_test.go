@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// newRateLimitRouter membuat router dengan policy satu request per menit per IP
+func newRateLimitRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	prev := ratelimit.Default
+	ratelimit.Default = ratelimit.NewMemoryStore()
+	t.Cleanup(func() { ratelimit.Default = prev })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+	r.Use(ErrorHandler())
+	policy := ratelimit.Policy{Name: "test", Limit: 1, Period: time.Minute}
+	r.GET("/api/courts", RateLimit(policy, RateLimitByIP), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func rateLimitedRequest(r *gin.Engine, remoteAddr, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/api/courts", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitByIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	// Tanpa proxy terpercaya, X-Forwarded-For yang diganti-ganti tetap masuk bucket IP koneksi
+	r := newRateLimitRouter(t, nil)
+
+	if code := rateLimitedRequest(r, "203.0.113.7:51000", "198.51.100.1"); code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", code)
+	}
+	if code := rateLimitedRequest(r, "203.0.113.7:51001", "198.51.100.2"); code != http.StatusTooManyRequests {
+		t.Fatalf("spoofed X-Forwarded-For status = %d, want 429", code)
+	}
+}
+
+func TestRateLimitByIPFromTrustedProxy(t *testing.T) {
+	// Di belakang proxy terpercaya setiap client punya bucket sendiri
+	r := newRateLimitRouter(t, []string{"10.0.0.0/8"})
+
+	for _, client := range []string{"198.51.100.1", "198.51.100.2"} {
+		if code := rateLimitedRequest(r, "10.0.0.5:443", client); code != http.StatusOK {
+			t.Fatalf("client %s status = %d, want 200", client, code)
+		}
+	}
+	if code := rateLimitedRequest(r, "10.0.0.5:443", "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("repeated client status = %d, want 429", code)
+	}
+}
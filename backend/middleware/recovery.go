@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery middleware menangkap panic di handler, mencatatnya beserta stack trace,
+// lalu mengembalikan response 500 dengan format apperror lewat ErrorHandler
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.ErrorContext(c.Request.Context(), "panic recovered",
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()),
+				)
+				abortWithError(c, apperror.Internal(fmt.Errorf("panic: %v", r)))
+			}
+		}()
+		c.Next()
+	}
+}
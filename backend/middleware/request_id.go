@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/HenryKristofani/GoFutsal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader adalah header yang dipakai untuk membawa request ID
+const RequestIDHeader = "X-Request-ID"
+
+// Request ID dari client hanya diterima jika formatnya aman untuk dicatat di log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID middleware membaca X-Request-ID dari client atau membuat yang baru,
+// lalu menyimpannya di context (termasuk context request untuk log)
+// dan mengirimkannya kembali di response header
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set("request_id", id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
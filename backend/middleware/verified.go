@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerifiedRequired middleware untuk memastikan email user sudah diverifikasi.
+// Status dibaca dari database supaya langsung berlaku tanpa menunggu token baru.
+// @Summary Email Verified Middleware
+// @Description Middleware untuk membatasi aksi (misalnya booking) ke akun yang emailnya sudah diverifikasi
+// @Security BearerAuth
+func EmailVerifiedRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var verified bool
+		err := config.DB.QueryRowContext(c.Request.Context(),
+			"SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1", c.GetInt("user_id"),
+		).Scan(&verified)
+		if err != nil {
+			abortWithError(c, apperror.FromDB(err, "User not found"))
+			return
+		}
+		if !verified {
+			abortWithError(c, apperror.New(http.StatusForbidden, apperror.CodeEmailNotVerified, "Please verify your email before making bookings"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// APIKey adalah credential untuk klien mesin (kiosk, partner). Secret tidak pernah
+// dikembalikan setelah key dibuat.
+type APIKey struct {
+	ID                 int        `json:"id"`
+	Name               string     `json:"name" example:"Kiosk meja depan"`
+	Prefix             string     `json:"prefix" example:"gfk_1a2b3c4d"`
+	Permissions        []string   `json:"permissions" example:"bookings:checkin,payments:create"`
+	VenueIDs           []int      `json:"venue_ids" example:"1"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute" example:"60"`
+	CreatedBy          *int       `json:"created_by"`
+	CreatedAt          time.Time  `json:"created_at"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	LastUsedAt         *time.Time `json:"last_used_at"`
+	LastUsedIP         *string    `json:"last_used_ip"`
+	RevokedAt          *time.Time `json:"revoked_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry adalah satu perubahan data di audit_log. Before dan After hanya berisi
+// field yang berubah (untuk update), before null untuk create dan after null untuk delete.
+type AuditEntry struct {
+	ID            int64           `json:"id"`
+	ActorUserID   *int            `json:"actor_user_id"`
+	ActorAPIKeyID *int            `json:"actor_api_key_id"`
+	Actor         string          `json:"actor" example:"admin"`
+	Action        string          `json:"action" example:"update"` // create, update, delete, restore, password_change, checkin
+	EntityType    string          `json:"entity_type" example:"court"`
+	EntityID      string          `json:"entity_id" example:"3"`
+	Before        json.RawMessage `json:"before" swaggertype:"object"`
+	After         json.RawMessage `json:"after" swaggertype:"object"`
+	IPAddress     string          `json:"ip_address" example:"203.0.113.7"`
+	RequestID     string          `json:"request_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
@@ -1,12 +1,55 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/venuetime"
+)
+
+// Booking menyimpan waktu mulai dan selesai sebagai timestamp dengan timezone.
+// Di JSON, booking_date, start_time dan end_time tetap dikirim dalam jam lokal
+// venue supaya client lama tetap bisa membaca response.
 type Booking struct {
-	ID           int    `json:"id" db:"id"`
-	CourtID      int    `json:"court_id" db:"court_id"`
-	UserID       int    `json:"user_id" db:"user_id"`
-	CustomerName string `json:"customer_name" db:"customer_name"`
-	BookingDate  string `json:"booking_date" db:"booking_date"`
-	StartTime    string `json:"start_time" db:"start_time"`
-	EndTime      string `json:"end_time" db:"end_time"`
-	TotalPrice   int    `json:"total_price" db:"total_price"`
+	ID           int        `json:"id" db:"id"`
+	CourtID      int        `json:"court_id" db:"court_id"`
+	UserID       int        `json:"user_id" db:"user_id"`
+	CustomerName string     `json:"customer_name" db:"customer_name"`
+	StartAt      time.Time  `json:"start_at" db:"start_at" example:"2025-12-31T19:00:00+07:00"`
+	EndAt        time.Time  `json:"end_at" db:"end_at" example:"2025-12-31T21:00:00+07:00"`
+	Timezone     string     `json:"timezone" example:"Asia/Jakarta"`
+	BookingDate  string     `json:"booking_date" example:"2025-12-31"`
+	StartTime    string     `json:"start_time" example:"19:00"`
+	EndTime      string     `json:"end_time" example:"21:00"`
+	TotalPrice   int        `json:"total_price" db:"total_price"`
+	CheckedInAt  *time.Time `json:"checked_in_at" db:"checked_in_at"`
+}
+
+// Location mengembalikan timezone venue tempat booking berlangsung
+func (b *Booking) Location() *time.Location {
+	loc, err := venuetime.Load(b.Timezone)
+	if err != nil {
+		return venuetime.Default()
+	}
+	return loc
+}
+
+// MarshalJSON mengisi booking_date, start_time dan end_time dari StartAt/EndAt
+// dalam jam lokal venue
+func (b Booking) MarshalJSON() ([]byte, error) {
+	loc := b.Location()
+	start := b.StartAt.In(loc)
+	end := b.EndAt.In(loc)
+
+	b.StartAt = start
+	b.EndAt = end
+	b.BookingDate = start.Format(venuetime.DateLayout)
+	b.StartTime = start.Format(venuetime.TimeLayout)
+	b.EndTime = end.Format(venuetime.TimeLayout)
+	if b.Timezone == "" {
+		b.Timezone = loc.String()
+	}
+
+	type booking Booking // hindari rekursi MarshalJSON
+	return json.Marshal(booking(b))
 }
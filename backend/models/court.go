@@ -2,8 +2,10 @@ package models
 
 type Court struct {
 	ID           int    `json:"id"`
+	VenueID      *int   `json:"venue_id" example:"1"`
 	Name         string `json:"name"`
 	Location     string `json:"location"`
 	PricePerHour int    `json:"price_per_hour"`
 	IsAvailable  bool   `json:"is_available"`
-}
\ No newline at end of file
+	Timezone     string `json:"timezone" example:"Asia/Jakarta"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// LoginAttempt adalah catatan audit satu percobaan login
+type LoginAttempt struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username" example:"johndoe"`
+	UserID    *int      `json:"user_id"`
+	IPAddress string    `json:"ip_address" example:"203.0.113.7"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason" example:"invalid_credentials"` // success, invalid_credentials, throttled, locked, mfa_pending, invalid_mfa
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Alasan pada LoginAttempt
+const (
+	LoginSuccess            = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginThrottled          = "throttled"
+	LoginLocked             = "locked"
+	LoginMFAPending         = "mfa_pending"
+	LoginInvalidMFA         = "invalid_mfa"
+)
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Metode pembayaran yang diterima di kasir
+const (
+	PaymentCash     = "cash"
+	PaymentTransfer = "transfer"
+	PaymentQRIS     = "qris"
+	PaymentCard     = "card"
+)
+
+// Payment adalah pembayaran yang diterima untuk satu booking
+type Payment struct {
+	ID               int       `json:"id"`
+	BookingID        int       `json:"booking_id"`
+	Amount           int       `json:"amount" example:"150000"`
+	Method           string    `json:"method" example:"cash"`
+	Reference        string    `json:"reference" example:"TRX-20251231-001"`
+	ReceivedBy       int       `json:"received_by"`
+	ReceivedByAPIKey *int      `json:"received_by_api_key,omitempty"` // diisi jika dicatat lewat API key (kiosk)
+	CreatedAt        time.Time `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// Role beserta permission yang dimilikinya
+type Role struct {
+	Name        string   `json:"name" example:"cashier"`
+	Description string   `json:"description" example:"Check-in booking dan menerima pembayaran"`
+	BuiltIn     bool     `json:"built_in"`
+	MFARequired bool     `json:"mfa_required"` // user dengan role ini wajib mengaktifkan 2FA
+	Permissions []string `json:"permissions" example:"bookings:checkin,payments:create"`
+}
+
+// Permission adalah hak akses yang bisa diberikan ke role
+type Permission struct {
+	Name        string `json:"name" example:"bookings:checkin"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Session adalah satu login aktif (perangkat) milik user
+type Session struct {
+	ID         string    `json:"id" example:"3q2-7wEAAAB0cnVl"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"`
+	IPAddress  string    `json:"ip_address" example:"203.0.113.7"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // session yang dipakai request ini
+}
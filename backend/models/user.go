@@ -1,5 +1,13 @@
 package models
 
+// Role bawaan. Role lain bisa dibuat admin lewat tabel roles.
+const (
+	RoleAdmin        = "admin"
+	RoleVenueManager = "venue_manager"
+	RoleCashier      = "cashier"
+	RoleClient       = "client"
+)
+
 // User represents a user account in the system
 // swagger:model User
 // @Description Data akun user untuk booking online
@@ -9,6 +17,8 @@ type User struct {
 	ID       int    `json:"id" db:"id"`
 	Username string `json:"username" db:"username"`
 	Email    string `json:"email" db:"email"`
-	Password string `json:"password" db:"password"`
-	Role     string `json:"role" db:"role" example:"client"` // admin atau client
+	Password string `json:"-" db:"password"`                 // hash bcrypt, tidak pernah dikirim ke client
+	Role     string `json:"role" db:"role" example:"client"` // nama role dari tabel roles
+
+	EmailVerified bool `json:"email_verified" example:"true"` // belum terverifikasi = belum bisa booking
 }
@@ -0,0 +1,15 @@
+package models
+
+// Venue adalah lokasi futsal yang memiliki beberapa lapangan
+type Venue struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name" example:"GoFutsal Sudirman"`
+	Address     string   `json:"address" example:"Jl. Sudirman No. 1, Jakarta"`
+	Latitude    *float64 `json:"latitude" example:"-6.2088"`
+	Longitude   *float64 `json:"longitude" example:"106.8456"`
+	Timezone    string   `json:"timezone" example:"Asia/Jakarta"`
+	OpeningTime string   `json:"opening_time" example:"08:00"`
+	ClosingTime string   `json:"closing_time" example:"23:00"`
+	Phone       string   `json:"phone" example:"+62215550100"`
+	Email       string   `json:"email" example:"sudirman@gofutsal.id"`
+}
@@ -0,0 +1,132 @@
+package purge
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/config"
+)
+
+// Purge job menghapus permanen baris soft delete (lapangan, booking, user) yang
+// sudah lewat masa retensi (SOFT_DELETE_RETENTION_DAYS, setiap PURGE_INTERVAL).
+
+// worker menyimpan status goroutine purge untuk health check
+var worker struct {
+	started  atomic.Bool
+	running  atomic.Bool
+	interval atomic.Int64
+	lastRun  atomic.Int64
+}
+
+// Result adalah jumlah baris yang dihapus permanen dalam satu kali purge
+type Result struct {
+	Bookings int64
+	Courts   int64
+	Users    int64
+}
+
+// Start menjalankan purge setiap interval sampai ctx dibatalkan, menghapus data
+// yang dihapus lebih dari retentionDays hari lalu. Tidak melakukan apa-apa jika retensi 0.
+func Start(ctx context.Context, retentionDays int, interval time.Duration) {
+	if retentionDays == 0 {
+		slog.Info("soft delete purge disabled (SOFT_DELETE_RETENTION_DAYS=0)")
+		return
+	}
+
+	retention := time.Duration(retentionDays) * 24 * time.Hour
+	worker.interval.Store(int64(interval))
+	worker.lastRun.Store(time.Now().UnixNano())
+	worker.started.Store(true)
+	worker.running.Store(true)
+	go func() {
+		defer worker.running.Store(false)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			res, err := Run(ctx, config.DB, retention)
+			worker.lastRun.Store(time.Now().UnixNano())
+			if err != nil {
+				slog.ErrorContext(ctx, "soft delete purge failed", "error", err)
+			} else if res.Bookings+res.Courts+res.Users > 0 {
+				slog.InfoContext(ctx, "soft delete purge completed",
+					"bookings", res.Bookings, "courts", res.Courts, "users", res.Users, "retention_days", retentionDays)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run menghapus permanen baris yang di-soft delete lebih dari retention yang lalu.
+// Lapangan baru dihapus jika tidak ada booking yang masih mengacu ke lapangan itu,
+// sedangkan referensi ke user di booking dan pembayaran dikosongkan lebih dulu.
+func Run(ctx context.Context, db *sql.DB, retention time.Duration) (Result, error) {
+	var res Result
+	cutoff := time.Now().Add(-retention)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	// Pembayaran ikut terhapus (ON DELETE CASCADE)
+	r, err := tx.ExecContext(ctx, "DELETE FROM bookings WHERE deleted_at < $1", cutoff)
+	if err != nil {
+		return res, err
+	}
+	res.Bookings, _ = r.RowsAffected()
+
+	r, err = tx.ExecContext(ctx, `
+		DELETE FROM courts c
+		WHERE c.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM bookings b WHERE b.court_id = c.id)
+	`, cutoff)
+	if err != nil {
+		return res, err
+	}
+	res.Courts, _ = r.RowsAffected()
+
+	purged := "SELECT id FROM users WHERE deleted_at < $1"
+	for _, q := range []string{
+		"UPDATE bookings SET user_id = NULL WHERE user_id IN (" + purged + ")",
+		"UPDATE bookings SET checked_in_by = NULL WHERE checked_in_by IN (" + purged + ")",
+		"UPDATE payments SET received_by = NULL WHERE received_by IN (" + purged + ")",
+	} {
+		if _, err := tx.ExecContext(ctx, q, cutoff); err != nil {
+			return res, err
+		}
+	}
+	r, err = tx.ExecContext(ctx, "DELETE FROM users WHERE deleted_at < $1", cutoff)
+	if err != nil {
+		return res, err
+	}
+	res.Users, _ = r.RowsAffected()
+
+	return res, tx.Commit()
+}
+
+// Health mengembalikan error jika job purge sudah dijalankan tetapi goroutine-nya
+// berhenti atau macet (tidak selesai satu putaran dalam dua kali interval).
+// Job yang dinonaktifkan dianggap sehat.
+func Health() error {
+	if !worker.started.Load() {
+		return nil
+	}
+	if !worker.running.Load() {
+		return errors.New("purge worker stopped")
+	}
+	interval := time.Duration(worker.interval.Load())
+	if since := time.Since(time.Unix(0, worker.lastRun.Load())); since > 2*interval {
+		return fmt.Errorf("purge worker stalled, last run %s ago", since.Round(time.Second))
+	}
+	return nil
+}
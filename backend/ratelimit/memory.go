@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/kvstore"
+)
+
+// sweepInterval adalah lama bucket tidak dipakai sebelum dihapus
+// (sudah penuh lagi untuk policy dengan Period kurang dari sweepInterval)
+const sweepInterval = time.Hour
+
+// MemoryStore menyimpan bucket di memory, hanya cocok untuk satu instance
+type MemoryStore struct {
+	buckets *kvstore.Map[Bucket]
+}
+
+// NewMemoryStore membuat MemoryStore kosong
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: kvstore.NewMap[Bucket](sweepInterval)}
+}
+
+func (m *MemoryStore) Take(_ context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	var res Result
+	m.buckets.Update(key, now, func(b Bucket) (Bucket, bool) {
+		tokens := policy.refill(b, now)
+		if tokens < 1 {
+			res = policy.result(false, tokens)
+			return b, false
+		}
+		res = policy.result(true, tokens-1)
+		return Bucket{Tokens: tokens - 1, Updated: now}, true
+	})
+	return res, nil
+}
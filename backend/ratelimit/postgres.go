@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// PostgresStore menyimpan bucket di tabel rate_limit_buckets supaya dipakai
+// bersama oleh semua instance aplikasi
+type PostgresStore struct {
+	db        *sql.DB
+	lastSweep atomic.Int64
+}
+
+// NewPostgresStore membuat PostgresStore dari koneksi database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	p := &PostgresStore{db: db}
+	p.lastSweep.Store(time.Now().UnixNano())
+	return p
+}
+
+func (p *PostgresStore) Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	// Refill dan ambil token dalam satu upsert atomic. Jika token kurang dari satu,
+	// baris tidak diubah (WHERE gagal) dan RETURNING kosong.
+	var tokens float64
+	err := p.db.QueryRowContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES ($1, $3::float8 - 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = LEAST($3::float8, rate_limit_buckets.tokens + GREATEST(EXTRACT(EPOCH FROM $2::timestamptz - rate_limit_buckets.updated_at)::float8, 0) * $4::float8) - 1,
+			updated_at = $2
+		WHERE LEAST($3::float8, rate_limit_buckets.tokens + GREATEST(EXTRACT(EPOCH FROM $2::timestamptz - rate_limit_buckets.updated_at)::float8, 0) * $4::float8) >= 1
+		RETURNING tokens
+	`, key, now, float64(policy.Limit), policy.rate()).Scan(&tokens)
+	if err == nil {
+		p.sweep(ctx, now)
+		return policy.result(true, tokens), nil
+	}
+	if err != sql.ErrNoRows {
+		return Result{}, err
+	}
+
+	// Ditolak: hitung isi bucket saat ini untuk header
+	var b Bucket
+	err = p.db.QueryRowContext(ctx,
+		"SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1", key,
+	).Scan(&b.Tokens, &b.Updated)
+	if err != nil {
+		return Result{}, err
+	}
+	return policy.result(false, policy.refill(b, now)), nil
+}
+
+// sweep menghapus bucket yang lama tidak dipakai, paling sering sekali per sweepInterval
+// untuk semua goroutine di instance ini
+func (p *PostgresStore) sweep(ctx context.Context, now time.Time) {
+	last := p.lastSweep.Load()
+	if now.Sub(time.Unix(0, last)) < sweepInterval || !p.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	if _, err := p.db.ExecContext(ctx, "DELETE FROM rate_limit_buckets WHERE updated_at < $1", now.Add(-sweepInterval)); err != nil {
+		slog.WarnContext(ctx, "rate limit bucket sweep failed", "error", err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package ratelimit membatasi jumlah request per client memakai token bucket:
+// setiap key punya bucket berisi Limit token yang terisi lagi secara merata
+// selama Period, dan setiap request mengambil satu token.
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/kvstore"
+)
+
+// Policy adalah batas request untuk satu kelompok route
+type Policy struct {
+	Name   string        // bagian dari key di store, harus unik per policy
+	Limit  int           // isi bucket maksimum (burst)
+	Period time.Duration // waktu untuk mengisi bucket dari kosong sampai penuh
+}
+
+// rate adalah jumlah token yang bertambah per detik
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// Bucket adalah isi bucket untuk satu key pada waktu Updated
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// refill menghitung isi bucket pada waktu now
+func (p Policy) refill(b Bucket, now time.Time) float64 {
+	if b.Updated.IsZero() {
+		return float64(p.Limit)
+	}
+	elapsed := max(now.Sub(b.Updated).Seconds(), 0)
+	return math.Min(float64(p.Limit), b.Tokens+elapsed*p.rate())
+}
+
+// Result adalah hasil mengambil token, dipakai untuk header RateLimit-*
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // waktu sampai bucket penuh lagi
+	RetryAfter time.Duration // waktu sampai ada satu token, 0 jika Allowed
+}
+
+// result menghitung Result dari isi bucket setelah request
+func (p Policy) result(allowed bool, tokens float64) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     p.Limit,
+		Remaining: int(tokens),
+		Reset:     time.Duration((float64(p.Limit) - tokens) / p.rate() * float64(time.Second)),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / p.rate() * float64(time.Second))
+	}
+	return res
+}
+
+// Store menyimpan bucket. MemoryStore untuk satu instance,
+// PostgresStore supaya limit berlaku bersama di semua instance.
+type Store interface {
+	// Take mengambil satu token dari bucket key secara atomic
+	Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error)
+}
+
+// Default adalah store yang dipakai aplikasi, diatur oleh Setup
+var Default Store = NewMemoryStore()
+
+// Setup memilih store dari backend ("memory" atau "postgres", default "memory")
+func Setup(backend string) {
+	Default = kvstore.Select(backend,
+		func() Store { return NewMemoryStore() },
+		func(db *sql.DB) Store { return NewPostgresStore(db) },
+	)
+}
+
+// Take mengambil satu token dari bucket identitas client untuk policy
+func Take(ctx context.Context, policy Policy, identity string) (Result, error) {
+	return Default.Take(ctx, "ratelimit:"+policy.Name+":"+identity, policy, time.Now())
+}
@@ -0,0 +1,100 @@
+package rbac
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HenryKristofani/GoFutsal/apikey"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Permission yang dipakai di routes. Daftar lengkap ada di tabel permissions.
+const (
+	VenuesAll       = "venues:all"
+	VenuesAdmin     = "venues:admin"
+	VenuesWrite     = "venues:write"
+	CourtsWrite     = "courts:write"
+	BookingsReadAll = "bookings:read_all"
+	BookingsCancel  = "bookings:cancel"
+	BookingsCheckin = "bookings:checkin"
+	PaymentsCreate  = "payments:create"
+	PaymentsRead    = "payments:read"
+	UsersManage     = "users:manage"
+	RolesManage     = "roles:manage"
+	APIKeysManage   = "apikeys:manage"
+	AuditRead       = "audit:read"
+)
+
+// Permission role di-cache sebentar supaya tidak query database di setiap request
+const cacheTTL = time.Minute
+
+type cacheEntry struct {
+	permissions map[string]bool
+	loadedAt    time.Time
+}
+
+var (
+	mu    sync.RWMutex
+	cache = map[string]cacheEntry{}
+)
+
+// RolePermissions mengembalikan semua permission milik role
+func RolePermissions(ctx context.Context, role string) (map[string]bool, error) {
+	mu.RLock()
+	entry, ok := cache[role]
+	mu.RUnlock()
+	if ok && time.Since(entry.loadedAt) < cacheTTL {
+		return entry.permissions, nil
+	}
+
+	rows, err := config.DB.QueryContext(ctx, "SELECT permission FROM role_permissions WHERE role = $1", role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := map[string]bool{}
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		permissions[p] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	cache[role] = cacheEntry{permissions: permissions, loadedAt: time.Now()}
+	mu.Unlock()
+
+	return permissions, nil
+}
+
+// HasPermission mengecek apakah role memiliki permission tertentu
+func HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	permissions, err := RolePermissions(ctx, role)
+	if err != nil {
+		return false, err
+	}
+	return permissions[permission], nil
+}
+
+// ContextHasPermission mengecek permission pemanggil request: permission API key
+// jika request memakai API key, selain itu permission role user yang login
+func ContextHasPermission(c *gin.Context, permission string) (bool, error) {
+	if key := apikey.FromContext(c); key != nil {
+		return key.Permissions[permission], nil
+	}
+	return HasPermission(c.Request.Context(), c.GetString("role"), permission)
+}
+
+// Invalidate menghapus cache permission, dipanggil setelah role diubah
+func Invalidate() {
+	mu.Lock()
+	cache = map[string]cacheEntry{}
+	mu.Unlock()
+}
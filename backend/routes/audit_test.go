@@ -0,0 +1,151 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/rbac"
+)
+
+// expectAudit mengharapkan satu baris audit_log untuk action dan entity tersebut
+func expectAudit(mock sqlmock.Sqlmock, action, entityType, entityID string) {
+	arg := sqlmock.AnyArg()
+	mock.ExpectExec(`INSERT INTO audit_log`).
+		WithArgs(arg, arg, arg, action, entityType, entityID, arg, arg, arg, arg).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectRevokeSessions(mock sqlmock.Sqlmock, userID int) {
+	mock.ExpectExec(`UPDATE sessions SET revoked_at`).WithArgs(userID, "").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at`).WithArgs(userID, "").WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestSecurityChangesAreAudited(t *testing.T) {
+	admin := caller{id: 1, role: "admin"}
+	row := func(before, after string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"old", "new"}).AddRow([]byte(before), []byte(after))
+	}
+
+	for name, tc := range map[string]struct {
+		method, path, body string
+		permission         string
+		expect             func(sqlmock.Sqlmock)
+	}{
+		"force logout": {
+			http.MethodDelete, "/api/admin/users/7/sessions", "", rbac.UsersManage,
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id FROM users`).WithArgs("7").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectBegin()
+				expectRevokeSessions(mock, 7)
+				mock.ExpectCommit()
+				expectAudit(mock, "force_logout", "user", "7")
+			},
+		},
+		"unlock login": {
+			http.MethodDelete, "/api/admin/users/7/lockout", "", rbac.UsersManage,
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT username FROM users`).WithArgs("7").
+					WillReturnRows(sqlmock.NewRows([]string{"username"}).AddRow("budi"))
+				expectAudit(mock, "unlock", "user", "7")
+			},
+		},
+		"revoke api key": {
+			http.MethodDelete, "/api/admin/api-keys/3", "", rbac.APIKeysManage,
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE api_keys k SET revoked_at`).WithArgs("3").
+					WillReturnRows(row(`{"id":3,"revoked_at":null}`, `{"id":3,"revoked_at":"2026-01-01T00:00:00Z"}`))
+				expectAudit(mock, "revoke", "api_key", "3")
+			},
+		},
+		"create role": {
+			http.MethodPost, "/api/admin/roles", `{"name":"cashier","description":"Kasir","permissions":["payments:create"]}`, rbac.RolesManage,
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO roles`).WithArgs("cashier", "Kasir").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM role_permissions`).WithArgs("cashier").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO role_permissions`).WithArgs("cashier", "payments:create").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				expectAudit(mock, "create", "role", "cashier")
+			},
+		},
+		"update role permissions": {
+			http.MethodPut, "/api/admin/roles/cashier/permissions", `{"permissions":["payments:read"]}`, rbac.RolesManage,
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM roles r WHERE r.name = \$1 FOR UPDATE`).WithArgs("cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"permissions"}).AddRow([]byte(`["payments:create"]`)))
+				mock.ExpectExec(`DELETE FROM role_permissions`).WithArgs("cashier").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO role_permissions`).WithArgs("cashier", "payments:read").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				expectAudit(mock, "update", "role", "cashier")
+			},
+		},
+		"require role mfa": {
+			http.MethodPut, "/api/admin/roles/cashier/mfa", `{"required":true}`, rbac.RolesManage,
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE roles r SET mfa_required`).WithArgs(true, "cashier").
+					WillReturnRows(row(`{"mfa_required":false}`, `{"mfa_required":true}`))
+				expectAudit(mock, "update", "role", "cashier")
+			},
+		},
+		"delete role": {
+			http.MethodDelete, "/api/admin/roles/cashier", "", rbac.RolesManage,
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT built_in FROM roles`).WithArgs("cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"built_in"}).AddRow(false))
+				mock.ExpectQuery(`DELETE FROM roles`).WithArgs("cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}).AddRow([]byte(`{"name":"cashier"}`)))
+				expectAudit(mock, "delete", "role", "cashier")
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock := newMock(t, admin)
+			expectMFANotMissing(mock, admin.id)
+			expectPermissions(mock, "admin", tc.permission)
+			tc.expect(mock)
+
+			w := do(t, admin, tc.method, tc.path, tc.body)
+			if w.Code != http.StatusOK && w.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want 2xx: %s", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestPasswordResetIsAudited(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.DB = db
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE password_reset_tokens SET used_at`).WithArgs(auth.HashToken("reset-token")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(7))
+	mock.ExpectExec(`UPDATE users SET password`).WithArgs(sqlmock.AnyArg(), 7).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRevokeSessions(mock, 7)
+	mock.ExpectCommit()
+	expectAudit(mock, "password_reset", "user", "7")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/reset-password",
+		strings.NewReader(`{"token":"reset-token","new_password":"RahasiaBaru456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+}
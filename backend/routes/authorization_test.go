@@ -0,0 +1,299 @@
+package routes_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/rbac"
+	"github.com/HenryKristofani/GoFutsal/routes"
+	"github.com/HenryKristofani/GoFutsal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// Test otorisasi lewat routes asli: JWT asli (kunci sementara), database diganti sqlmock.
+// Setiap query yang tidak diharapkan membuat request gagal, jadi test yang lolos juga
+// membuktikan tidak ada UPDATE yang dijalankan untuk request yang ditolak.
+
+var router *gin.Engine
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+	config.DB = db
+	if err := validation.Register(); err != nil {
+		panic(err)
+	}
+
+	cfg := config.Default()
+	cfg.RateLimit.Enabled = false
+	router = gin.New()
+	routes.SetupRoutes(router, &cfg)
+
+	os.Exit(m.Run())
+}
+
+// caller adalah user yang mengirim request
+type caller struct {
+	id   int
+	role string
+}
+
+// newMock memasang database mock untuk satu test dan mengharapkan pengecekan session caller
+func newMock(t *testing.T, who caller) sqlmock.Sqlmock {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.DB = db
+	rbac.Invalidate()
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	mock.ExpectQuery(`FROM sessions`).
+		WithArgs("sid-test", who.id, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	return mock
+}
+
+func expectPermissions(mock sqlmock.Sqlmock, role string, permissions ...string) {
+	rows := sqlmock.NewRows([]string{"permission"})
+	for _, p := range permissions {
+		rows.AddRow(p)
+	}
+	mock.ExpectQuery(`SELECT permission FROM role_permissions`).WithArgs(role).WillReturnRows(rows)
+}
+
+func expectMFANotMissing(mock sqlmock.Sqlmock, userID int) {
+	mock.ExpectQuery(`totp_enabled_at IS NULL`).WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"missing"}).AddRow(false))
+}
+
+func do(t *testing.T, who caller, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	token, err := auth.GenerateJWT(who.id, "user", "user@example.com", who.role, "sid-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUpdateOtherUserRoleWithoutUsersManage(t *testing.T) {
+	client := caller{id: 5, role: "client"}
+	mock := newMock(t, client)
+	expectPermissions(mock, "client")
+
+	w := do(t, client, http.MethodPut, "/api/users/7", `{"username":"other","email":"other@example.com","role":"admin"}`)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body)
+	}
+}
+
+func TestUpdateUserRoleRequiresRolesManage(t *testing.T) {
+	// users:manage boleh mengubah data user lain, tetapi tidak role-nya
+	support := caller{id: 5, role: "support"}
+	mock := newMock(t, support)
+	expectPermissions(mock, "support", rbac.UsersManage)
+	expectMFANotMissing(mock, support.id)
+	mock.ExpectQuery(`SELECT role FROM users`).WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("client"))
+	expectPermissions(mock, "client")
+
+	w := do(t, support, http.MethodPut, "/api/users/7", `{"username":"other","email":"other@example.com","role":"admin"}`)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body)
+	}
+}
+
+func TestUpdatePrivilegedUserRequiresRolesManage(t *testing.T) {
+	// users:manage tidak cukup untuk mengganti email admin (lalu reset password akunnya)
+	support := caller{id: 5, role: "support"}
+	mock := newMock(t, support)
+	expectPermissions(mock, "support", rbac.UsersManage)
+	expectMFANotMissing(mock, support.id)
+	mock.ExpectQuery(`SELECT role FROM users`).WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("admin"))
+	expectPermissions(mock, "admin", rbac.UsersManage, rbac.RolesManage)
+
+	w := do(t, support, http.MethodPut, "/api/users/1", `{"username":"admin","email":"attacker@example.com"}`)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body)
+	}
+}
+
+func TestUpdateClientWithUsersManage(t *testing.T) {
+	support := caller{id: 5, role: "support"}
+	mock := newMock(t, support)
+	expectPermissions(mock, "support", rbac.UsersManage)
+	expectMFANotMissing(mock, support.id)
+	mock.ExpectQuery(`SELECT role FROM users`).WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("client"))
+	expectPermissions(mock, "client")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE users u SET`).WithArgs("other", "new@example.com", "client", "7").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email_changed", "role_changed", "old", "new"}).
+			AddRow(7, true, false, []byte(`{"email":"other@example.com"}`), []byte(`{"email":"new@example.com"}`)))
+	mock.ExpectCommit()
+	mock.ExpectExec(`INSERT INTO audit_log`).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := do(t, support, http.MethodPut, "/api/users/7", `{"username":"other","email":"new@example.com"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+}
+
+func TestSelfUpdateCannotRaiseOwnRole(t *testing.T) {
+	client := caller{id: 5, role: "client"}
+	mock := newMock(t, client)
+	expectMFANotMissing(mock, client.id)
+	mock.ExpectQuery(`SELECT role FROM users`).WithArgs("5").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("client"))
+	expectPermissions(mock, "client")
+
+	w := do(t, client, http.MethodPut, "/api/users/5", `{"username":"myself","email":"me@example.com","role":"admin"}`)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body)
+	}
+}
+
+func TestVenueManagerCannotAssignRoles(t *testing.T) {
+	manager := caller{id: 3, role: "venue_manager"}
+	mock := newMock(t, manager)
+	expectMFANotMissing(mock, manager.id)
+	expectPermissions(mock, "venue_manager", rbac.VenuesWrite, rbac.CourtsWrite, rbac.BookingsReadAll, rbac.BookingsCancel)
+
+	w := do(t, manager, http.MethodPut, "/api/admin/users/3/role", `{"role":"admin"}`)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body)
+	}
+}
+
+func TestVenueManagerCannotTouchOtherVenue(t *testing.T) {
+	manager := caller{id: 3, role: "venue_manager"}
+	mock := newMock(t, manager)
+	expectMFANotMissing(mock, manager.id)
+	expectPermissions(mock, "venue_manager", rbac.VenuesWrite, rbac.CourtsWrite, rbac.BookingsReadAll, rbac.BookingsCancel)
+	mock.ExpectQuery(`SELECT c.venue_id FROM bookings`).WithArgs("9").
+		WillReturnRows(sqlmock.NewRows([]string{"venue_id"}).AddRow(2))
+	mock.ExpectQuery(`FROM venue_managers`).WithArgs(manager.id, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	w := do(t, manager, http.MethodDelete, "/api/admin/bookings/9", "")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body)
+	}
+}
+
+func TestUserBookingRoutesAreScopedToCaller(t *testing.T) {
+	// Venue manager memakai route akun untuk booking milik user lain: 404, bukan dihapus
+	manager := caller{id: 3, role: "venue_manager"}
+	mock := newMock(t, manager)
+	mock.ExpectQuery(`UPDATE bookings b SET deleted_at`).WithArgs("9", manager.id).
+		WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}))
+
+	w := do(t, manager, http.MethodDelete, "/api/bookings/9", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: %s", w.Code, w.Body)
+	}
+}
+
+func TestRoleChangeRevokesSessions(t *testing.T) {
+	admin := caller{id: 1, role: "admin"}
+	for name, tc := range map[string]struct {
+		method, path, body string
+		before             func(sqlmock.Sqlmock)
+	}{
+		"update user": {
+			http.MethodPut, "/api/users/7", `{"username":"other","email":"other@example.com","role":"venue_manager"}`,
+			func(mock sqlmock.Sqlmock) {
+				expectPermissions(mock, "admin", rbac.UsersManage, rbac.RolesManage)
+				expectMFANotMissing(mock, admin.id)
+				mock.ExpectQuery(`SELECT role FROM users`).WithArgs("7").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("client"))
+				expectPermissions(mock, "client")
+				mock.ExpectQuery(`FROM roles WHERE name`).WithArgs("venue_manager").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE users u SET`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "email_changed", "role_changed", "old", "new"}).
+						AddRow(7, false, true, []byte(`{"role":"client"}`), []byte(`{"role":"venue_manager"}`)))
+			},
+		},
+		"assign role": {
+			http.MethodPut, "/api/admin/users/7/role", `{"role":"venue_manager"}`,
+			func(mock sqlmock.Sqlmock) {
+				expectMFANotMissing(mock, admin.id)
+				expectPermissions(mock, "admin", rbac.UsersManage, rbac.RolesManage)
+				mock.ExpectQuery(`FROM roles WHERE name`).WithArgs("venue_manager").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE users u SET role`).WithArgs("venue_manager", "7").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "changed", "old", "new"}).
+						AddRow(7, true, []byte(`{"role":"client"}`), []byte(`{"role":"venue_manager"}`)))
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock := newMock(t, admin)
+			tc.before(mock)
+			mock.ExpectExec(`UPDATE sessions SET revoked_at`).WithArgs(7, "").WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at`).WithArgs(7, "").WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectCommit()
+			mock.ExpectExec(`INSERT INTO audit_log`).WillReturnResult(sqlmock.NewResult(1, 1))
+
+			w := do(t, admin, tc.method, tc.path, tc.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestAccessTokenWithoutSessionIsRejected(t *testing.T) {
+	// Tanpa sid tidak ada session yang bisa dicabut, jadi token ditolak sebelum query apa pun
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.DB = db
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	token, err := auth.GenerateJWT(5, "user", "user@example.com", "admin", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/bookings", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,185 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HenryKristofani/GoFutsal/auth"
+	"github.com/HenryKristofani/GoFutsal/config"
+	"github.com/HenryKristofani/GoFutsal/loginguard"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const mfaSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// newAnonymousMock memasang database mock dan login guard baru untuk request tanpa login
+func newAnonymousMock(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.DB = db
+	prev := loginguard.Default
+	loginguard.Default = loginguard.NewMemoryStore()
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		loginguard.Default = prev
+		db.Close()
+	})
+	return mock
+}
+
+func post(path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func expectLoginAttempt(mock sqlmock.Sqlmock, success bool, reason string) {
+	arg := sqlmock.AnyArg()
+	mock.ExpectExec(`INSERT INTO login_attempts`).
+		WithArgs("budi", 7, arg, arg, success, reason).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestLoginWithMFAReturnsOnlyMFAToken(t *testing.T) {
+	mock := newAnonymousMock(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(`FROM users`).WithArgs("budi").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "password", "role", "verified", "mfa"}).
+			AddRow(7, "budi", "budi@example.com", string(hash), "admin", true, true))
+	expectLoginAttempt(mock, false, "mfa_pending")
+
+	w := post("/api/auth/login", `{"username":"budi","password":"Rahasia123"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	// Sebelum kode 2FA diverifikasi tidak ada data akun (email, role) maupun token akses
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"user", "access_token", "refresh_token"} {
+		if _, ok := body[field]; ok {
+			t.Errorf("MFA-pending response contains %q: %s", field, w.Body)
+		}
+	}
+	if body["mfa_required"] != true {
+		t.Errorf("mfa_required = %v, want true", body["mfa_required"])
+	}
+	token, _ := body["mfa_token"].(string)
+	if claims, err := auth.ValidateMFAToken(token); err != nil || claims.UserID != 7 {
+		t.Errorf("mfa_token is not a valid MFA token for user 7: %v", err)
+	}
+}
+
+func TestLoginMFA(t *testing.T) {
+	step := auth.TOTPStep(time.Now())
+	code, err := auth.TOTPCode(mfaSecret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		// expect mengatur query verifikasi kode (TOTP atau kode pemulihan)
+		expect     func(mock sqlmock.Sqlmock)
+		wantStatus int
+	}{
+		{
+			name: "totp code",
+			code: code,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE users SET totp_last_step = \$1 WHERE id = \$2 AND totp_last_step < \$1`).
+					WithArgs(step, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			// Kode untuk langkah yang sudah dipakai login sebelumnya bukan kode TOTP yang valid lagi,
+			// jadi hanya dicocokkan sebagai kode pemulihan (dan tidak ada)
+			name:     "replayed totp step",
+			code:     code,
+			lastStep: step + 1,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE user_recovery_codes SET used_at`).
+					WithArgs(7, auth.HashToken(code)).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			// Request paralel dengan kode yang sama: hanya satu yang berhasil menaikkan totp_last_step
+			name: "concurrent totp replay",
+			code: code,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE users SET totp_last_step`).
+					WithArgs(step, 7).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "recovery code",
+			code: "ABCDE-FGHIJ",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE user_recovery_codes SET used_at = NOW\(\) WHERE user_id = \$1 AND code_hash = \$2 AND used_at IS NULL`).
+					WithArgs(7, auth.HashToken("abcdefghij")).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "used recovery code",
+			code: "abcde-fghij",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE user_recovery_codes SET used_at`).
+					WithArgs(7, auth.HashToken("abcdefghij")).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newAnonymousMock(t)
+			mfaToken, err := auth.GenerateMFAToken(7)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mock.ExpectQuery(`SELECT id, username, email, role, email_verified_at IS NOT NULL, totp_secret, totp_last_step`).
+				WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "role", "verified", "secret", "last_step"}).
+					AddRow(7, "budi", "budi@example.com", "admin", true, mfaSecret, tt.lastStep))
+			tt.expect(mock)
+			if tt.wantStatus == http.StatusOK {
+				expectLoginAttempt(mock, true, "success")
+				mock.ExpectExec(`INSERT INTO sessions`).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO refresh_tokens`).WillReturnResult(sqlmock.NewResult(1, 1))
+			} else {
+				expectLoginAttempt(mock, false, "invalid_mfa")
+			}
+
+			w := post("/api/auth/login/mfa", `{"mfa_token":"`+mfaToken+`","code":"`+tt.code+`"}`)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(w.Body.String(), `"access_token"`) {
+				t.Errorf("response has no access token: %s", w.Body)
+			}
+		})
+	}
+}
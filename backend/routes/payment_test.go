@@ -0,0 +1,66 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HenryKristofani/GoFutsal/rbac"
+)
+
+// cashier mengelola venue 2 dan boleh check-in serta mencatat pembayaran
+var cashier = caller{id: 4, role: "cashier"}
+
+// expectCashierVenue mengharapkan pengecekan akses kasir ke venue booking 9
+func expectCashierVenue(mock sqlmock.Sqlmock) {
+	expectMFANotMissing(mock, cashier.id)
+	expectPermissions(mock, "cashier", rbac.BookingsCheckin, rbac.PaymentsCreate)
+	mock.ExpectQuery(`SELECT c.venue_id FROM bookings`).WithArgs("9").
+		WillReturnRows(sqlmock.NewRows([]string{"venue_id"}).AddRow(2))
+	mock.ExpectQuery(`FROM venue_managers`).WithArgs(cashier.id, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+}
+
+func TestCheckInSkipsDeletedBooking(t *testing.T) {
+	tests := []struct {
+		name       string
+		stillLive  bool // booking masih ada saat dicek ulang
+		wantStatus int
+	}{
+		{"deleted after venue check", false, http.StatusNotFound},
+		{"already checked in", true, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMock(t, cashier)
+			expectCashierVenue(mock)
+			mock.ExpectQuery(`UPDATE bookings b SET checked_in_at = NOW\(\).* AND b.deleted_at IS NULL`).
+				WithArgs(cashier.id, "9", nil).
+				WillReturnRows(sqlmock.NewRows([]string{"old", "new"}))
+			rows := sqlmock.NewRows([]string{"venue_id"})
+			if tt.stillLive {
+				rows.AddRow(2)
+			}
+			mock.ExpectQuery(`SELECT c.venue_id FROM bookings .* AND b.deleted_at IS NULL`).WithArgs("9").WillReturnRows(rows)
+
+			w := do(t, cashier, http.MethodPost, "/api/admin/bookings/9/checkin", "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
+
+func TestPaymentRequiresLiveBooking(t *testing.T) {
+	// Booking dihapus setelah pengecekan venue: INSERT tidak menghasilkan baris, jadi 404
+	mock := newMock(t, cashier)
+	expectCashierVenue(mock)
+	mock.ExpectQuery(`INSERT INTO payments .* SELECT b.id, .* FROM bookings b WHERE b.id = \$1 AND b.deleted_at IS NULL`).
+		WithArgs("9", 150000, "cash", "", cashier.id, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "created_at", "to_jsonb"}))
+
+	w := do(t, cashier, http.MethodPost, "/api/admin/bookings/9/payments", `{"amount":150000,"method":"cash"}`)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: %s", w.Code, w.Body)
+	}
+}
@@ -1,17 +1,59 @@
 package routes
 
 import (
-	"net/http"
+	"time"
 
+	"github.com/HenryKristofani/GoFutsal/apperror"
 	"github.com/HenryKristofani/GoFutsal/config"
 	"github.com/HenryKristofani/GoFutsal/controllers"
+	"github.com/HenryKristofani/GoFutsal/metrics"
 	"github.com/HenryKristofani/GoFutsal/middleware"
+	"github.com/HenryKristofani/GoFutsal/ratelimit"
+	"github.com/HenryKristofani/GoFutsal/rbac"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func SetupRoutes(r *gin.Engine) {
+// Policy rate limit per kelompok route. Login guard tetap membatasi gagal login per akun,
+// policy auth di sini membatasi jumlah request dari satu IP (termasuk register dan reset password).
+// Refresh dipanggil otomatis oleh frontend, jadi punya bucket sendiri yang lebih longgar supaya
+// user di belakang NAT yang sama tidak terblokir. API key dibatasi limit miliknya sendiri di AuthRequired.
+var (
+	authRateLimit    = ratelimit.Policy{Name: "auth", Limit: 10, Period: time.Minute}
+	refreshRateLimit = ratelimit.Policy{Name: "refresh", Limit: 60, Period: time.Minute}
+	publicRateLimit  = ratelimit.Policy{Name: "public", Limit: 120, Period: time.Minute}
+	clientRateLimit  = ratelimit.Policy{Name: "client", Limit: 300, Period: time.Minute}
+)
+
+func SetupRoutes(r *gin.Engine, cfg *config.Config) {
+	// Span tracing dipasang paling luar supaya log dan query di dalam request masuk ke trace yang sama.
+	// Request ID, access log dan error handler dipasang berikutnya
+	// supaya semua log membawa request ID dan semua error memakai format response yang sama.
+	// Recovery dipasang setelah error handler supaya panic juga dijawab dengan format apperror.
+	r.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	r.Use(middleware.RequestID())
+	r.Use(middleware.AccessLog())
+	r.Use(middleware.Metrics())
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.Recovery())
+
+	// Deadline untuk setiap request, query database yang terlalu lama dibatalkan
+	r.Use(middleware.RequestTimeout(cfg.Server.RequestTimeout))
+
 	// Add CORS middleware
-	r.Use(middleware.CORS())
+	r.Use(middleware.CORS(cfg.CORS))
+
+	r.NoRoute(func(c *gin.Context) {
+		c.Error(apperror.NotFound("Route not found"))
+	})
+
+	// rateLimit memasang policy rate limit, tidak melakukan apa-apa jika RATE_LIMIT_ENABLED=false
+	rateLimit := func(policy ratelimit.Policy, key middleware.RateLimitKey) gin.HandlerFunc {
+		if !cfg.RateLimit.Enabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return middleware.RateLimit(policy, key)
+	}
 
 	// Public API routes
 	api := r.Group("/api")
@@ -19,67 +61,143 @@ func SetupRoutes(r *gin.Engine) {
 		// AUTHENTICATION (Public routes)
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", controllers.Login)
-			auth.POST("/refresh", controllers.RefreshToken)
+			auth.POST("/refresh", rateLimit(refreshRateLimit, middleware.RateLimitByIP), controllers.RefreshToken)
+
+			credentials := auth.Group("/")
+			credentials.Use(rateLimit(authRateLimit, middleware.RateLimitByIP))
+			credentials.POST("/login", controllers.Login)
+			credentials.POST("/login/mfa", controllers.LoginMFA)
+			credentials.POST("/forgot-password", controllers.ForgotPassword)
+			credentials.POST("/reset-password", controllers.ResetPassword)
+			credentials.POST("/verify-email", controllers.VerifyEmail)
 		}
 
 		// PUBLIC ROUTES
-		api.POST("/users/register", controllers.RegisterUser)
+		api.POST("/users/register", rateLimit(authRateLimit, middleware.RateLimitByIP), controllers.RegisterUser)
 
 		// Public court info (can be viewed without auth)
-		api.GET("/courts", controllers.GetCourts)
-		api.GET("/courts/:id", controllers.GetCourtByID)
+		public := api.Group("/")
+		public.Use(rateLimit(publicRateLimit, middleware.RateLimitByIP))
+		public.GET("/courts", controllers.GetCourts)
+		public.GET("/courts/:id", controllers.GetCourtByID)
+
+		// Public venue info
+		public.GET("/venues", controllers.GetVenues)
+		public.GET("/venues/:id", controllers.GetVenueByID)
+		public.GET("/venues/:id/courts", controllers.GetVenueCourts)
 	}
 
-	// Protected API routes (requires JWT)
+	// Protected API routes (requires JWT atau API key), dibatasi per user atau per API key
 	protected := api.Group("/")
 	protected.Use(middleware.AuthRequired())
+	protected.Use(rateLimit(clientRateLimit, middleware.RateLimitByUser))
+
+	// Route akun user (tidak bisa dipakai dengan API key)
+	account := protected.Group("/")
+	account.Use(middleware.UserRequired())
 	{
 		// USER PROFILE
-		protected.GET("/profile", controllers.GetProfile)
-
-		// USER CRUD (protected)
-		protected.GET("/users", controllers.GetUsers)
-		protected.GET("/users/:id", controllers.GetUserByID)
-		protected.PUT("/users/:id", controllers.UpdateUser)
-		protected.DELETE("/users/:id", controllers.DeleteUser)
-
-		// BOOKING routes (user can manage their bookings)
-		protected.GET("/bookings", controllers.GetBookings)
-		protected.POST("/bookings", controllers.CreateBooking)
-		protected.GET("/bookings/:id", controllers.GetBookingByID)
-		protected.PUT("/bookings/:id", controllers.UpdateBooking)
-		protected.DELETE("/bookings/:id", controllers.DeleteBooking)
+		account.GET("/profile", controllers.GetProfile)
+		account.PUT("/profile", controllers.UpdateProfile)
+		account.PUT("/profile/password", controllers.ChangePassword)
+		account.POST("/auth/resend-verification", controllers.ResendVerification)
+
+		// SESSIONS (perangkat yang sedang login)
+		account.GET("/profile/sessions", controllers.GetSessions)
+		account.DELETE("/profile/sessions/:id", controllers.RevokeSession)
+
+		// TWO-FACTOR AUTHENTICATION
+		account.POST("/profile/2fa/enroll", controllers.EnrollMFA)
+		account.POST("/profile/2fa/verify", controllers.VerifyMFA)
+		account.POST("/profile/2fa/recovery-codes", controllers.RegenerateRecoveryCodes)
+		account.POST("/profile/2fa/disable", controllers.DisableMFA)
+
+		// BOOKING routes (user can manage their bookings).
+		// Membuat dan mengubah booking butuh email yang sudah diverifikasi.
+		// Membuat booking mendukung Idempotency-Key supaya request ulang tidak membuat booking ganda.
+		account.GET("/bookings", controllers.GetBookings)
+		account.POST("/bookings", middleware.EmailVerifiedRequired(), middleware.Idempotency(cfg.Server.MaxBodyBytes), controllers.CreateBooking)
+		account.GET("/bookings/:id", controllers.GetBookingByID)
+		account.PUT("/bookings/:id", middleware.EmailVerifiedRequired(), controllers.UpdateBooking)
+		account.DELETE("/bookings/:id", controllers.DeleteBooking)
 	}
 
-	// Admin routes (requires JWT + admin role)
-	admin := protected.Group("/admin")
-	admin.Use(middleware.AdminRequired())
 	{
-		// COURT MANAGEMENT (admin only)
-		admin.POST("/courts", controllers.CreateCourt)
-		admin.PUT("/courts/:id", controllers.UpdateCourt)
-		admin.DELETE("/courts/:id", controllers.DeleteCourt)
+		// USER CRUD (akun sendiri, atau semua user dengan permission users:manage)
+		protected.GET("/users", middleware.RequirePermission(rbac.UsersManage), middleware.MFAEnforced(), controllers.GetUsers)
+		protected.GET("/users/:id", middleware.SelfOrPermission("id", rbac.UsersManage), middleware.MFAEnforced(), controllers.GetUserByID)
+		protected.PUT("/users/:id", middleware.SelfOrPermission("id", rbac.UsersManage), middleware.MFAEnforced(), controllers.UpdateUser)
+		protected.DELETE("/users/:id", middleware.SelfOrPermission("id", rbac.UsersManage), middleware.MFAEnforced(), controllers.DeleteUser)
 	}
 
-	// Health check and test endpoints (public)
-	r.GET("/api", controllers.TestEndpoint)
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
-			"message": "GoFutsal API is running",
-			"version": "1.0.0",
-		})
-	})
+	// Admin & staff routes (requires JWT atau API key + permission).
+	// Staff selain admin dan API key hanya boleh mengelola venue yang ditugaskan, dicek di controller.
+	// Role yang diwajibkan 2FA harus sudah mengaktifkan 2FA.
+	admin := protected.Group("/admin")
+	admin.Use(middleware.MFAEnforced())
+	{
+		// COURT MANAGEMENT
+		admin.POST("/courts", middleware.RequirePermission(rbac.CourtsWrite), controllers.CreateCourt)
+		admin.PUT("/courts/:id", middleware.RequirePermission(rbac.CourtsWrite), controllers.UpdateCourt)
+		admin.DELETE("/courts/:id", middleware.RequirePermission(rbac.CourtsWrite), controllers.DeleteCourt)
+		admin.POST("/courts/:id/restore", middleware.RequirePermission(rbac.CourtsWrite), controllers.RestoreCourt)
+
+		// BOOKING MANAGEMENT
+		admin.GET("/bookings", middleware.RequirePermission(rbac.BookingsReadAll), controllers.GetManagedBookings)
+		admin.DELETE("/bookings/:id", middleware.RequirePermission(rbac.BookingsCancel), controllers.DeleteManagedBooking)
+		admin.POST("/bookings/:id/restore", middleware.RequirePermission(rbac.BookingsCancel), controllers.RestoreBooking)
+		admin.POST("/bookings/:id/checkin", middleware.RequirePermission(rbac.BookingsCheckin), controllers.CheckInBooking)
+		admin.GET("/bookings/:id/payments", middleware.RequirePermission(rbac.PaymentsRead), controllers.GetBookingPayments)
+		admin.POST("/bookings/:id/payments", middleware.RequirePermission(rbac.PaymentsCreate), middleware.Idempotency(cfg.Server.MaxBodyBytes), controllers.CreatePayment)
+
+		// LOGIN SECURITY
+		admin.GET("/login-attempts", middleware.RequirePermission(rbac.UsersManage), controllers.GetLoginAttempts)
+		admin.DELETE("/users/:id/lockout", middleware.RequirePermission(rbac.UsersManage), controllers.UnlockUser)
+		admin.POST("/users/:id/restore", middleware.RequirePermission(rbac.UsersManage), controllers.RestoreUser)
+		admin.GET("/users/:id/sessions", middleware.RequirePermission(rbac.UsersManage), controllers.GetUserSessions)
+		admin.DELETE("/users/:id/sessions", middleware.RequirePermission(rbac.UsersManage), controllers.ForceLogoutUser)
+
+		// VENUE MANAGEMENT
+		admin.POST("/venues", middleware.RequirePermission(rbac.VenuesAdmin), controllers.CreateVenue)
+		admin.PUT("/venues/:id", middleware.RequirePermission(rbac.VenuesWrite), controllers.UpdateVenue)
+		admin.DELETE("/venues/:id", middleware.RequirePermission(rbac.VenuesAdmin), controllers.DeleteVenue)
+		admin.GET("/venues/:id/managers", middleware.RequirePermission(rbac.VenuesAdmin), controllers.GetVenueManagers)
+		admin.POST("/venues/:id/managers", middleware.RequirePermission(rbac.VenuesAdmin), controllers.AddVenueManager)
+		admin.DELETE("/venues/:id/managers/:user_id", middleware.RequirePermission(rbac.VenuesAdmin), controllers.RemoveVenueManager)
+
+		// AUDIT LOG
+		admin.GET("/audit", middleware.RequirePermission(rbac.AuditRead), controllers.GetAuditLog)
 
-	// Database check endpoint (public)
-	r.GET("/dbcheck", func(c *gin.Context) {
-		var version string
-		err := config.DB.QueryRow("SELECT version();").Scan(&version)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+		// API KEY MANAGEMENT
+		admin.GET("/api-keys", middleware.RequirePermission(rbac.APIKeysManage), controllers.GetAPIKeys)
+		admin.POST("/api-keys", middleware.RequirePermission(rbac.APIKeysManage), controllers.CreateAPIKey)
+		admin.DELETE("/api-keys/:id", middleware.RequirePermission(rbac.APIKeysManage), controllers.RevokeAPIKey)
+
+		// ROLE & PERMISSION MANAGEMENT
+		roles := admin.Group("/")
+		roles.Use(middleware.RequirePermission(rbac.RolesManage))
+		{
+			roles.GET("/roles", controllers.GetRoles)
+			roles.POST("/roles", controllers.CreateRole)
+			roles.PUT("/roles/:name/permissions", controllers.UpdateRolePermissions)
+			roles.PUT("/roles/:name/mfa", controllers.UpdateRoleMFA)
+			roles.DELETE("/roles/:name", controllers.DeleteRole)
+			roles.GET("/permissions", controllers.GetPermissions)
+			roles.PUT("/users/:id/role", controllers.AssignUserRole)
 		}
-		c.JSON(http.StatusOK, gin.H{"postgres_version": version})
-	})
+	}
+
+	// Metrics Prometheus (request, DB pool, bisnis), bisa dilindungi dengan METRICS_TOKEN
+	metrics.RegisterDB(config.DB)
+	r.GET("/metrics", middleware.MetricsToken(cfg.Metrics.Token), gin.WrapH(metrics.Handler()))
+
+	// Public key untuk verifikasi JWT oleh service lain
+	r.GET("/.well-known/jwks.json", controllers.JWKS)
+
+	// Health check and test endpoints (public).
+	// /livez untuk liveness probe, /readyz untuk readiness probe (DB, migrasi, worker).
+	r.GET("/api", controllers.TestEndpoint)
+	r.GET("/health", controllers.Health)
+	r.GET("/livez", controllers.Livez)
+	r.GET("/readyz", controllers.Readyz)
 }
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing memakai OpenTelemetry dengan exporter otlp, stdout atau none.
+// Endpoint OTLP dan sampler memakai env standar OpenTelemetry
+// (OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_TRACES_SAMPLER, ...).
+// Trace context dari frontend dibaca dari header W3C traceparent/tracestate.
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+
+	instrumentation = "github.com/HenryKristofani/GoFutsal"
+)
+
+// Setup memasang propagator W3C dan tracer provider dengan exporter yang dipilih.
+// Fungsi shutdown yang dikembalikan harus dipanggil saat server berhenti
+// supaya span yang tersisa sempat dikirim.
+func Setup(ctx context.Context, exporterName, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch name := strings.ToLower(exporterName); name {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (use otlp, stdout or none)", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tp := NewProvider(serviceName, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// NewProvider membuat tracer provider dengan resource service ini.
+// Untuk test, pakai exporter in-memory secara sinkron, misalnya
+// NewProvider("gofutsal", sdktrace.WithSyncer(exp)) dengan exp dari tracetest.NewInMemoryExporter.
+func NewProvider(serviceName string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(attribute.String("service.name", serviceName))
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, opts...)...)
+}
+
+// Start membuat span baru dari tracer global, untuk operasi yang ingin diukur
+// sendiri (misalnya bcrypt)
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, opts...)
+}
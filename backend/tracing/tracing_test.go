@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestAndQuerySpans(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := NewProvider("gofutsal-test", sdktrace.WithSyncer(exp))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		tp.Shutdown(t.Context())
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(otelgin.Middleware("gofutsal-test"))
+	r.GET("/api/courts/:id", func(c *gin.Context) {
+		// Query dijalankan dengan context request seperti QueryContext di controller
+		var tracer QueryTracer
+		ctx := tracer.TraceQueryStart(c.Request.Context(), nil, pgx.TraceQueryStartData{
+			SQL: "SELECT id, name FROM courts WHERE id = $1",
+		})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/courts/42", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2: %v", len(spans), spans)
+	}
+	// Span DB selesai lebih dulu daripada span request
+	db, req := spans[0], spans[1]
+
+	if req.SpanKind != trace.SpanKindServer {
+		t.Errorf("request span kind = %v, want server", req.SpanKind)
+	}
+	if got := attr(req.Attributes, "http.route"); got != "/api/courts/:id" {
+		t.Errorf("http.route = %q, want /api/courts/:id", got)
+	}
+
+	if db.Name != "db SELECT" {
+		t.Errorf("db span name = %q, want %q", db.Name, "db SELECT")
+	}
+	if db.SpanKind != trace.SpanKindClient {
+		t.Errorf("db span kind = %v, want client", db.SpanKind)
+	}
+	if got := attr(db.Attributes, "db.system.name"); got != "postgresql" {
+		t.Errorf("db.system.name = %q, want postgresql", got)
+	}
+	if got := attr(db.Attributes, "db.response.rows_affected"); got != "1" {
+		t.Errorf("db.response.rows_affected = %q, want 1", got)
+	}
+
+	if db.SpanContext.TraceID() != req.SpanContext.TraceID() {
+		t.Errorf("db span trace %s, want %s", db.SpanContext.TraceID(), req.SpanContext.TraceID())
+	}
+	if db.Parent.SpanID() != req.SpanContext.SpanID() {
+		t.Errorf("db span parent %s, want request span %s", db.Parent.SpanID(), req.SpanContext.SpanID())
+	}
+}
+
+func attr(attrs []attribute.KeyValue, key attribute.Key) string {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
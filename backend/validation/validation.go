@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/venuetime"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxBookingDuration adalah durasi maksimal satu booking
+const MaxBookingDuration = 12 * time.Hour
+
+var usernameChars = func(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Register mendaftarkan validator custom ke validator milik Gin.
+// Harus dipanggil sekali sebelum server mulai menerima request.
+func Register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("unexpected validator engine %T", binding.Validator.Engine())
+	}
+
+	// Pakai nama field JSON di pesan error, bukan nama field struct
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	validators := map[string]validator.Func{
+		"bookingdate": isDate,
+		"timeofday":   isTimeOfDay,
+		"timerange":   isTimeRange,
+		"username":    isUsername,
+		"password":    isStrongPassword,
+	}
+	for tag, fn := range validators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("register validation %q: %w", tag, err)
+		}
+	}
+
+	apperror.RegisterMessage("bookingdate", func(validator.FieldError) string {
+		return "must be a date in YYYY-MM-DD format"
+	})
+	apperror.RegisterMessage("timeofday", func(validator.FieldError) string {
+		return "must be a time in HH:MM format"
+	})
+	apperror.RegisterMessage("timerange", func(fe validator.FieldError) string {
+		return fmt.Sprintf("must be after %s and at most %d hours later",
+			toSnakeCase(fe.Param()), int(MaxBookingDuration.Hours()))
+	})
+	apperror.RegisterMessage("username", func(validator.FieldError) string {
+		return "must be 3-50 characters of letters, digits, '_' or '.'"
+	})
+	apperror.RegisterMessage("password", func(validator.FieldError) string {
+		return "must be at least 8 characters and contain upper case, lower case and digit"
+	})
+
+	return nil
+}
+
+func isDate(fl validator.FieldLevel) bool {
+	_, err := time.Parse(venuetime.DateLayout, fl.Field().String())
+	return err == nil
+}
+
+func isTimeOfDay(fl validator.FieldLevel) bool {
+	_, err := time.Parse(venuetime.TimeLayout, fl.Field().String())
+	return err == nil
+}
+
+// isTimeRange membandingkan jam selesai dengan jam mulai di field lain,
+// contoh: `binding:"timerange=StartTime"`. Jam selesai yang lebih kecil dari
+// jam mulai berarti booking melewati tengah malam.
+func isTimeRange(fl validator.FieldLevel) bool {
+	other, _, _, ok := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+	if !ok || other.Kind() != reflect.String {
+		return false
+	}
+	if _, err := time.Parse(venuetime.TimeLayout, other.String()); err != nil {
+		// Format jam mulai sudah dilaporkan oleh validator field tersebut
+		return true
+	}
+
+	d, err := venuetime.Duration(other.String(), fl.Field().String())
+	if err != nil {
+		return false
+	}
+	// Jam yang sama dianggap 24 jam, jadi otomatis ditolak oleh batas durasi
+	return d <= MaxBookingDuration
+}
+
+func isUsername(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if len(s) < 3 || len(s) > 50 {
+		return false
+	}
+	return strings.IndexFunc(s, func(r rune) bool { return !usernameChars(r) }) == -1
+}
+
+// isStrongPassword: minimal 8 karakter, ada huruf besar, huruf kecil dan angka
+func isStrongPassword(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if len(s) < 8 || len(s) > 72 { // bcrypt hanya memakai 72 byte pertama
+		return false
+	}
+
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// toSnakeCase mengubah nama field struct (StartTime) menjadi nama JSON (start_time)
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
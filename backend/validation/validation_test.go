@@ -0,0 +1,170 @@
+package validation
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/HenryKristofani/GoFutsal/apperror"
+	"github.com/HenryKristofani/GoFutsal/dto"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestMain(m *testing.M) {
+	if err := Register(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// invalidFields menjalankan validator Gin dan mengembalikan field JSON yang gagal beserta pesannya
+func invalidFields(t *testing.T, req any) map[string]string {
+	t.Helper()
+	err := binding.Validator.ValidateStruct(req)
+	if err == nil {
+		return nil
+	}
+	appErr := apperror.FromBinding(err)
+	if appErr.Code != apperror.CodeValidationFailed {
+		t.Fatalf("FromBinding code = %s, want %s", appErr.Code, apperror.CodeValidationFailed)
+	}
+	fields := map[string]string{}
+	for _, d := range appErr.Details {
+		fields[d.Field] = d.Message
+	}
+	return fields
+}
+
+func checkFields(t *testing.T, req any, want ...string) {
+	t.Helper()
+	got := invalidFields(t, req)
+	if len(got) != len(want) {
+		t.Fatalf("invalid fields = %v, want %v", got, want)
+	}
+	for _, field := range want {
+		if _, ok := got[field]; !ok {
+			t.Fatalf("invalid fields = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestBookingRequest(t *testing.T) {
+	valid := dto.BookingRequest{
+		CourtID:      1,
+		CustomerName: "Budi",
+		BookingDate:  "2025-12-31",
+		StartTime:    "19:00",
+		EndTime:      "21:00",
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *dto.BookingRequest)
+		want   []string // field JSON yang gagal
+	}{
+		{"valid", func(r *dto.BookingRequest) {}, nil},
+		{"crosses midnight", func(r *dto.BookingRequest) { r.StartTime, r.EndTime = "22:00", "01:00" }, nil},
+		{"ends at midnight", func(r *dto.BookingRequest) { r.StartTime, r.EndTime = "22:00", "00:00" }, nil},
+		{"maximum duration", func(r *dto.BookingRequest) { r.StartTime, r.EndTime = "08:00", "20:00" }, nil},
+		{"longer than maximum", func(r *dto.BookingRequest) { r.StartTime, r.EndTime = "08:00", "20:01" }, []string{"end_time"}},
+		{"same start and end", func(r *dto.BookingRequest) { r.EndTime = r.StartTime }, []string{"end_time"}},
+		{"missing court", func(r *dto.BookingRequest) { r.CourtID = 0 }, []string{"court_id"}},
+		{"negative court", func(r *dto.BookingRequest) { r.CourtID = -1 }, []string{"court_id"}},
+		{"long customer name", func(r *dto.BookingRequest) { r.CustomerName = strings.Repeat("a", 101) }, []string{"customer_name"}},
+		{"date with slashes", func(r *dto.BookingRequest) { r.BookingDate = "31/12/2025" }, []string{"booking_date"}},
+		{"impossible date", func(r *dto.BookingRequest) { r.BookingDate = "2025-02-30" }, []string{"booking_date"}},
+		{"time with seconds", func(r *dto.BookingRequest) { r.StartTime = "19:00:00" }, []string{"start_time"}},
+		{"hour out of range", func(r *dto.BookingRequest) { r.EndTime = "24:00" }, []string{"end_time"}},
+		{"missing end", func(r *dto.BookingRequest) { r.EndTime = "" }, []string{"end_time"}},
+		// Format jam mulai yang salah hanya dilaporkan di start_time
+		{"invalid start only", func(r *dto.BookingRequest) { r.StartTime = "7pm" }, []string{"start_time"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			checkFields(t, &req, tt.want...)
+		})
+	}
+}
+
+func TestTimeRangeMessage(t *testing.T) {
+	fields := invalidFields(t, &dto.BookingRequest{
+		CourtID: 1, CustomerName: "Budi", BookingDate: "2025-12-31", StartTime: "08:00", EndTime: "21:00",
+	})
+	if want := "must be after start_time and at most 12 hours later"; fields["end_time"] != want {
+		t.Errorf("end_time message = %q, want %q", fields["end_time"], want)
+	}
+}
+
+func TestRegisterUserRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		email    string
+		password string
+		want     []string
+	}{
+		{"valid", "budi_santoso.99", "budi@example.com", "Rahasia123", nil},
+		{"unicode letters", "bùdí", "budi@example.com", "Rahasia123", nil},
+		{"short username", "bd", "budi@example.com", "Rahasia123", []string{"username"}},
+		{"long username", strings.Repeat("b", 51), "budi@example.com", "Rahasia123", []string{"username"}},
+		{"username with @", "budi@home", "budi@example.com", "Rahasia123", []string{"username"}},
+		{"username with space", "budi santoso", "budi@example.com", "Rahasia123", []string{"username"}},
+		{"invalid email", "budi", "budi.example.com", "Rahasia123", []string{"email"}},
+		{"short password", "budi", "budi@example.com", "Rah123", []string{"password"}},
+		{"password without upper case", "budi", "budi@example.com", "rahasia123", []string{"password"}},
+		{"password without lower case", "budi", "budi@example.com", "RAHASIA123", []string{"password"}},
+		{"password without digit", "budi", "budi@example.com", "RahasiaSekali", []string{"password"}},
+		{"password over bcrypt limit", "budi", "budi@example.com", "Ra1" + strings.Repeat("a", 70), []string{"password"}},
+		{"everything missing", "", "", "", []string{"username", "email", "password"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkFields(t, &dto.RegisterUserRequest{Username: tt.username, Email: tt.email, Password: tt.password}, tt.want...)
+		})
+	}
+}
+
+func TestChangePasswordRequest(t *testing.T) {
+	checkFields(t, &dto.ChangePasswordRequest{CurrentPassword: "Rahasia123", NewPassword: "RahasiaBaru456"})
+	// Password baru harus berbeda dari yang lama
+	checkFields(t, &dto.ChangePasswordRequest{CurrentPassword: "Rahasia123", NewPassword: "Rahasia123"}, "new_password")
+}
+
+func TestCourtRequest(t *testing.T) {
+	valid := dto.CourtRequest{Name: "Lapangan A", Location: "Jl. Sudirman No. 1", PricePerHour: 150000}
+
+	tests := []struct {
+		name   string
+		modify func(r *dto.CourtRequest)
+		want   []string
+	}{
+		{"valid", func(r *dto.CourtRequest) {}, nil},
+		{"with venue and timezone", func(r *dto.CourtRequest) { r.VenueID, r.Timezone = 2, "Asia/Makassar" }, nil},
+		{"unknown timezone", func(r *dto.CourtRequest) { r.Timezone = "Asia/Bandung" }, []string{"timezone"}},
+		{"negative venue", func(r *dto.CourtRequest) { r.VenueID = -1 }, []string{"venue_id"}},
+		{"free court", func(r *dto.CourtRequest) { r.PricePerHour = 0 }, []string{"price_per_hour"}},
+		{"long name", func(r *dto.CourtRequest) { r.Name = strings.Repeat("a", 101) }, []string{"name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			checkFields(t, &req, tt.want...)
+		})
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"StartTime": "start_time",
+		"EndTime":   "end_time",
+		"name":      "name",
+	}
+	for in, want := range tests {
+		if got := toSnakeCase(in); got != want {
+			t.Errorf("toSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package venuetime
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Layout tanggal dan jam lokal venue yang dipakai di API
+const (
+	DateLayout = "2006-01-02"
+	TimeLayout = "15:04"
+)
+
+// DefaultTimezone dipakai jika SetDefault belum dipanggil (WIB)
+const DefaultTimezone = "Asia/Jakarta"
+
+var (
+	mu        sync.RWMutex
+	locations = map[string]*time.Location{}
+	fallback  *time.Location
+)
+
+// SetDefault mengatur timezone default venue (VENUE_TIMEZONE)
+func SetDefault(name string) error {
+	loc, err := Load(name)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	fallback = loc
+	mu.Unlock()
+	return nil
+}
+
+// Default mengembalikan timezone default venue, DefaultTimezone jika SetDefault belum dipanggil
+func Default() *time.Location {
+	mu.RLock()
+	loc := fallback
+	mu.RUnlock()
+	if loc != nil {
+		return loc
+	}
+
+	loc, _ = Load(DefaultTimezone)
+	mu.Lock()
+	fallback = loc
+	mu.Unlock()
+	return loc
+}
+
+// Load mengembalikan *time.Location untuk nama IANA (contoh: Asia/Makassar).
+// Hasilnya di-cache karena time.LoadLocation membaca file setiap kali dipanggil.
+// Nama kosong berarti timezone default.
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		return Default(), nil
+	}
+
+	mu.RLock()
+	loc, ok := locations[name]
+	mu.RUnlock()
+	if ok {
+		return loc, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+	}
+
+	mu.Lock()
+	locations[name] = loc
+	mu.Unlock()
+	return loc, nil
+}
+
+// Range mengubah tanggal dan jam lokal venue menjadi waktu mulai dan selesai.
+// Jika jam selesai tidak lebih besar dari jam mulai, booking dianggap
+// melewati tengah malam dan selesai di hari berikutnya.
+func Range(date, start, end string, loc *time.Location) (time.Time, time.Time, error) {
+	startAt, err := time.ParseInLocation(DateLayout+" "+TimeLayout, date+" "+start, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	endClock, err := time.Parse(TimeLayout, end)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endAt := time.Date(startAt.Year(), startAt.Month(), startAt.Day(),
+		endClock.Hour(), endClock.Minute(), 0, 0, loc)
+	if !endAt.After(startAt) {
+		endAt = endAt.AddDate(0, 0, 1)
+	}
+
+	return startAt, endAt, nil
+}
+
+// Duration menghitung durasi antara dua jam lokal dengan aturan yang sama seperti Range
+func Duration(start, end string) (time.Duration, error) {
+	s, err := time.Parse(TimeLayout, start)
+	if err != nil {
+		return 0, err
+	}
+	e, err := time.Parse(TimeLayout, end)
+	if err != nil {
+		return 0, err
+	}
+	if !e.After(s) {
+		e = e.AddDate(0, 0, 1)
+	}
+	return e.Sub(s), nil
+}
+
+// WithinHours memastikan booking [startAt, endAt) berada di dalam jam buka venue.
+// Jam tutup yang tidak lebih besar dari jam buka berarti venue tutup setelah
+// tengah malam, sehingga jam buka hari sebelumnya juga diperiksa.
+func WithinHours(startAt, endAt time.Time, opening, closing string, loc *time.Location) bool {
+	local := startAt.In(loc)
+	for _, day := range []time.Time{local.AddDate(0, 0, -1), local} {
+		open, close, err := Range(day.Format(DateLayout), opening, closing, loc)
+		if err != nil {
+			return false
+		}
+		if !startAt.Before(open) && !endAt.After(close) {
+			return true
+		}
+	}
+	return false
+}
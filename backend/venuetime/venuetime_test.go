@@ -0,0 +1,159 @@
+package venuetime
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestRange(t *testing.T) {
+	jakarta := mustLoad(t, "Asia/Jakarta")
+	makassar := mustLoad(t, "Asia/Makassar")
+	newYork := mustLoad(t, "America/New_York")
+	london := mustLoad(t, "Europe/London")
+
+	tests := []struct {
+		name       string
+		date       string
+		start, end string
+		loc        *time.Location
+		wantStart  string // RFC 3339 dalam UTC
+		wantEnd    string
+		wantLength time.Duration
+	}{
+		{"same day", "2025-12-31", "19:00", "21:00", jakarta, "2025-12-31T12:00:00Z", "2025-12-31T14:00:00Z", 2 * time.Hour},
+		{"other timezone", "2025-12-31", "19:00", "21:00", makassar, "2025-12-31T11:00:00Z", "2025-12-31T13:00:00Z", 2 * time.Hour},
+		{"crosses midnight", "2025-12-31", "23:00", "01:00", jakarta, "2025-12-31T16:00:00Z", "2025-12-31T18:00:00Z", 2 * time.Hour},
+		{"ends at midnight", "2025-12-31", "22:00", "00:00", jakarta, "2025-12-31T15:00:00Z", "2025-12-31T17:00:00Z", 2 * time.Hour},
+		{"crosses month end", "2025-02-28", "23:30", "00:30", jakarta, "2025-02-28T16:30:00Z", "2025-02-28T17:30:00Z", time.Hour},
+		{"same start and end is a full day", "2025-12-31", "08:00", "08:00", jakarta, "2025-12-31T01:00:00Z", "2026-01-01T01:00:00Z", 24 * time.Hour},
+		// 9 Maret 2025 jam 02:00 di New York maju ke 03:00: durasi jam dinding 00:00-04:00 hanya 3 jam
+		{"dst starts", "2025-03-09", "00:00", "04:00", newYork, "2025-03-09T05:00:00Z", "2025-03-09T08:00:00Z", 3 * time.Hour},
+		// 2 November 2025 jam 02:00 di New York mundur ke 01:00: 00:00-04:00 menjadi 5 jam
+		{"dst ends", "2025-11-02", "00:00", "04:00", newYork, "2025-11-02T04:00:00Z", "2025-11-02T09:00:00Z", 5 * time.Hour},
+		// Booking melewati tengah malam pada malam pergantian DST di London (30 Maret 2025 01:00 -> 02:00)
+		{"crosses midnight into dst", "2025-03-29", "23:00", "03:00", london, "2025-03-29T23:00:00Z", "2025-03-30T02:00:00Z", 3 * time.Hour},
+		{"crosses midnight out of dst", "2025-10-25", "23:00", "03:00", london, "2025-10-25T22:00:00Z", "2025-10-26T03:00:00Z", 5 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := Range(tt.date, tt.start, tt.end, tt.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := start.UTC().Format(time.RFC3339); got != tt.wantStart {
+				t.Errorf("start = %s, want %s", got, tt.wantStart)
+			}
+			if got := end.UTC().Format(time.RFC3339); got != tt.wantEnd {
+				t.Errorf("end = %s, want %s", got, tt.wantEnd)
+			}
+			if got := end.Sub(start); got != tt.wantLength {
+				t.Errorf("length = %v, want %v", got, tt.wantLength)
+			}
+			if start.Location() != tt.loc || end.Location() != tt.loc {
+				t.Errorf("locations = %v, %v; want %v", start.Location(), end.Location(), tt.loc)
+			}
+		})
+	}
+}
+
+func TestRangeInvalid(t *testing.T) {
+	tests := []struct {
+		name             string
+		date, start, end string
+	}{
+		{"bad date", "31-12-2025", "19:00", "21:00"},
+		{"impossible date", "2025-02-30", "19:00", "21:00"},
+		{"bad start", "2025-12-31", "7pm", "21:00"},
+		{"bad end", "2025-12-31", "19:00", "25:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Range(tt.date, tt.start, tt.end, time.UTC); err == nil {
+				t.Error("Range() = nil error, want error")
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		start, end string
+		want       time.Duration
+	}{
+		{"19:00", "21:00", 2 * time.Hour},
+		{"23:00", "01:00", 2 * time.Hour},
+		{"22:30", "00:00", 90 * time.Minute},
+		{"08:00", "08:00", 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := Duration(tt.start, tt.end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Duration(%s, %s) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+	if _, err := Duration("19:00", "9pm"); err == nil {
+		t.Error("Duration with invalid end = nil error, want error")
+	}
+}
+
+func TestWithinHours(t *testing.T) {
+	jakarta := mustLoad(t, "Asia/Jakarta")
+	booking := func(date, start, end string) (time.Time, time.Time) {
+		s, e, err := Range(date, start, end, jakarta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s, e
+	}
+
+	tests := []struct {
+		name             string
+		date, start, end string
+		opening, closing string
+		want             bool
+	}{
+		{"inside", "2025-12-31", "19:00", "21:00", "08:00", "22:00", true},
+		{"exactly opening hours", "2025-12-31", "08:00", "22:00", "08:00", "22:00", true},
+		{"before opening", "2025-12-31", "07:00", "09:00", "08:00", "22:00", false},
+		{"after closing", "2025-12-31", "21:00", "23:00", "08:00", "22:00", false},
+		// Venue buka 16:00 sampai 02:00 hari berikutnya
+		{"late venue evening", "2025-12-31", "22:00", "01:00", "16:00", "02:00", true},
+		{"late venue after midnight", "2026-01-01", "00:30", "01:30", "16:00", "02:00", true},
+		{"late venue past closing", "2026-01-01", "01:00", "03:00", "16:00", "02:00", false},
+		{"late venue morning", "2026-01-01", "10:00", "12:00", "16:00", "02:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := booking(tt.date, tt.start, tt.end)
+			if got := WithinHours(start, end, tt.opening, tt.closing, jakarta); got != tt.want {
+				t.Errorf("WithinHours = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := Load("Asia/Bandung"); err == nil {
+		t.Error("Load unknown timezone = nil error, want error")
+	}
+	a, _ := Load("Asia/Makassar")
+	b, _ := Load("Asia/Makassar")
+	if a != b {
+		t.Error("Load does not cache locations")
+	}
+	if loc, _ := Load(""); loc != Default() {
+		t.Errorf("Load(\"\") = %v, want default %v", loc, Default())
+	}
+}
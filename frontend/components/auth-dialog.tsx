@@ -3,7 +3,7 @@
 import type React from "react"
 
 import { useState } from "react"
-import { AuthAPI, type LoginRequest, type RegisterRequest } from "@/lib/auth"
+import { AuthAPI, type LoginRequest, type LoginResult, type RegisterRequest } from "@/lib/auth"
 import { Button } from "@/components/ui/button"
 import { Dialog, DialogContent, DialogDescription, DialogHeader, DialogTitle } from "@/components/ui/dialog"
 import { Input } from "@/components/ui/input"
@@ -24,6 +24,33 @@ export function AuthDialog({ open, onOpenChange, mode, onModeChange }: AuthDialo
   const [isLoading, setIsLoading] = useState(false)
   const [error, setError] = useState("")
   const [success, setSuccess] = useState("")
+  // Diisi jika akun memakai 2FA: form berganti menjadi input kode
+  const [mfaToken, setMfaToken] = useState("")
+  const [mfaCode, setMfaCode] = useState("")
+
+  const cancelMFA = () => {
+    setMfaToken("")
+    setMfaCode("")
+    setError("")
+    setSuccess("")
+  }
+
+  const handleLoginResult = (result: LoginResult) => {
+    if (result.success) {
+      setSuccess(result.message)
+      // Close dialog and reload to update auth state
+      setTimeout(() => {
+        onOpenChange(false)
+        window.location.reload()
+      }, 1000)
+    } else if (result.mfaRequired && result.mfaToken) {
+      setMfaToken(result.mfaToken)
+      setPassword("") // Clear password for security
+      setSuccess(result.message)
+    } else {
+      setError(result.message)
+    }
+  }
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault()
@@ -52,24 +79,15 @@ export function AuthDialog({ open, onOpenChange, mode, onModeChange }: AuthDialo
         } else {
           setError(result.message)
         }
+      } else if (mfaToken) {
+        handleLoginResult(await AuthAPI.loginMFA(mfaToken, mfaCode.trim()))
       } else {
         const loginData: LoginRequest = {
           username,
           password,
         }
 
-        const result = await AuthAPI.login(loginData)
-        
-        if (result.success) {
-          setSuccess(result.message)
-          // Close dialog and reload to update auth state
-          setTimeout(() => {
-            onOpenChange(false)
-            window.location.reload()
-          }, 1000)
-        } else {
-          setError(result.message)
-        }
+        handleLoginResult(await AuthAPI.login(loginData))
       }
     } catch (err) {
       setError("Terjadi kesalahan yang tidak terduga")
@@ -105,49 +123,73 @@ export function AuthDialog({ open, onOpenChange, mode, onModeChange }: AuthDialo
             </div>
           )}
 
-          <div className="space-y-1.5 sm:space-y-2">
-            <Label htmlFor="username" className="text-sm sm:text-base">Username</Label>
-            <Input
-              id="username"
-              type="text"
-              placeholder="Masukkan username"
-              value={username}
-              onChange={(e) => setUsername(e.target.value)}
-              required
-              disabled={isLoading}
-              className="h-10 sm:h-11 text-sm sm:text-base"
-            />
-          </div>
-
-          {mode === "register" && (
+          {mfaToken ? (
             <div className="space-y-1.5 sm:space-y-2">
-              <Label htmlFor="email" className="text-sm sm:text-base">Email</Label>
+              <Label htmlFor="mfa-code" className="text-sm sm:text-base">Kode 2FA</Label>
               <Input
-                id="email"
-                type="email"
-                placeholder="nama@email.com"
-                value={email}
-                onChange={(e) => setEmail(e.target.value)}
+                id="mfa-code"
+                type="text"
+                inputMode="numeric"
+                autoComplete="one-time-code"
+                placeholder="123456 atau kode pemulihan"
+                value={mfaCode}
+                onChange={(e) => setMfaCode(e.target.value)}
                 required
+                autoFocus
+                maxLength={20}
                 disabled={isLoading}
                 className="h-10 sm:h-11 text-sm sm:text-base"
               />
             </div>
-          )}
+          ) : (
+            <>
+              <div className="space-y-1.5 sm:space-y-2">
+                <Label htmlFor="username" className="text-sm sm:text-base">
+                  {mode === "login" ? "Username atau email" : "Username"}
+                </Label>
+                <Input
+                  id="username"
+                  type="text"
+                  placeholder={mode === "login" ? "Masukkan username atau email" : "Masukkan username"}
+                  value={username}
+                  onChange={(e) => setUsername(e.target.value)}
+                  required
+                  disabled={isLoading}
+                  className="h-10 sm:h-11 text-sm sm:text-base"
+                />
+              </div>
 
-          <div className="space-y-1.5 sm:space-y-2">
-            <Label htmlFor="password" className="text-sm sm:text-base">Password</Label>
-            <Input
-              id="password"
-              type="password"
-              placeholder="••••••••"
-              value={password}
-              onChange={(e) => setPassword(e.target.value)}
-              required
-              disabled={isLoading}
-              className="h-10 sm:h-11 text-sm sm:text-base"
-            />
-          </div>
+              {mode === "register" && (
+                <div className="space-y-1.5 sm:space-y-2">
+                  <Label htmlFor="email" className="text-sm sm:text-base">Email</Label>
+                  <Input
+                    id="email"
+                    type="email"
+                    placeholder="nama@email.com"
+                    value={email}
+                    onChange={(e) => setEmail(e.target.value)}
+                    required
+                    disabled={isLoading}
+                    className="h-10 sm:h-11 text-sm sm:text-base"
+                  />
+                </div>
+              )}
+
+              <div className="space-y-1.5 sm:space-y-2">
+                <Label htmlFor="password" className="text-sm sm:text-base">Password</Label>
+                <Input
+                  id="password"
+                  type="password"
+                  placeholder="••••••••"
+                  value={password}
+                  onChange={(e) => setPassword(e.target.value)}
+                  required
+                  disabled={isLoading}
+                  className="h-10 sm:h-11 text-sm sm:text-base"
+                />
+              </div>
+            </>
+          )}
 
           <Button type="submit" className="w-full h-10 sm:h-11 text-sm sm:text-base" size="lg" disabled={isLoading}>
             {isLoading ? (
@@ -169,7 +211,15 @@ export function AuthDialog({ open, onOpenChange, mode, onModeChange }: AuthDialo
           </Button>
 
           <div className="text-center text-xs sm:text-sm">
-            {mode === "login" ? (
+            {mfaToken ? (
+              <button
+                type="button"
+                onClick={cancelMFA}
+                className="text-primary hover:underline font-medium touch-target"
+              >
+                Kembali ke login
+              </button>
+            ) : mode === "login" ? (
               <p className="text-muted-foreground">
                 Belum punya akun?{" "}
                 <button
@@ -5,14 +5,37 @@ interface User {
   username: string;
   email: string;
   role: string;
+  email_verified?: boolean;
 }
 
+// Jika akun memakai 2FA, login hanya mengembalikan mfa_required dan mfa_token;
+// token baru didapat setelah kode dikirim ke /api/auth/login/mfa
 interface LoginResponse {
   success: boolean;
   message: string;
-  access_token: string;
-  refresh_token: string;
-  user: User;
+  access_token?: string;
+  refresh_token?: string;
+  mfa_required?: boolean;
+  mfa_token?: string;
+  user?: User;
+}
+
+interface LoginResult {
+  success: boolean;
+  message: string;
+  user?: User;
+  mfaRequired?: boolean;
+  mfaToken?: string;
+}
+
+interface ApiErrorResponse {
+  success: false;
+  error: {
+    code: string;
+    message: string;
+    details?: { field: string; message: string }[];
+    request_id?: string;
+  };
 }
 
 interface RegisterRequest {
@@ -146,7 +169,7 @@ class AuthAPI {
       if (!response.ok) {
         return { 
           success: false, 
-          message: result.error || 'Registrasi gagal' 
+          message: (result as ApiErrorResponse).error?.message || 'Registrasi gagal' 
         };
       }
 
@@ -163,30 +186,33 @@ class AuthAPI {
     }
   }
 
-  static async login(data: LoginRequest): Promise<{ success: boolean; message: string; user?: User }> {
+  static async login(data: LoginRequest): Promise<LoginResult> {
     try {
       const response = await this.makeRequest('/api/auth/login', {
         method: 'POST',
         body: JSON.stringify(data),
       });
 
-      const result: LoginResponse = await response.json();
+      const result = await response.json();
 
       if (!response.ok) {
         return { 
           success: false, 
-          message: result.message || 'Login gagal' 
+          message: (result as ApiErrorResponse).error?.message || 'Login gagal' 
         };
       }
 
-      // Store tokens securely
-      TokenManager.setTokens(result.access_token, result.refresh_token, result.user);
+      // Akun dengan 2FA: belum ada token, minta kode lalu panggil loginMFA
+      if ((result as LoginResponse).mfa_required) {
+        return {
+          success: false,
+          mfaRequired: true,
+          mfaToken: result.mfa_token,
+          message: 'Masukkan kode dari aplikasi authenticator atau kode pemulihan',
+        };
+      }
 
-      return { 
-        success: true, 
-        message: result.message,
-        user: result.user 
-      };
+      return this.completeLogin(result);
     } catch (error) {
       return { 
         success: false, 
@@ -195,6 +221,47 @@ class AuthAPI {
     }
   }
 
+  // Langkah kedua login untuk akun dengan 2FA
+  static async loginMFA(mfaToken: string, code: string): Promise<LoginResult> {
+    try {
+      const response = await this.makeRequest('/api/auth/login/mfa', {
+        method: 'POST',
+        body: JSON.stringify({ mfa_token: mfaToken, code }),
+      });
+
+      const result = await response.json();
+
+      if (!response.ok) {
+        return {
+          success: false,
+          message: (result as ApiErrorResponse).error?.message || 'Kode 2FA tidak valid'
+        };
+      }
+
+      return this.completeLogin(result);
+    } catch (error) {
+      return {
+        success: false,
+        message: 'Terjadi kesalahan koneksi'
+      };
+    }
+  }
+
+  private static completeLogin(result: LoginResponse): LoginResult {
+    if (!result.access_token || !result.refresh_token || !result.user) {
+      return { success: false, message: 'Login gagal' };
+    }
+
+    // Store tokens securely
+    TokenManager.setTokens(result.access_token, result.refresh_token, result.user);
+
+    return { 
+      success: true, 
+      message: result.message,
+      user: result.user 
+    };
+  }
+
   static async refreshAccessToken(): Promise<string | null> {
     try {
       const response = await this.makeRequest('/api/auth/refresh', {
@@ -245,4 +312,4 @@ class AuthAPI {
 }
 
 export { AuthAPI, TokenManager };
-export type { User, LoginRequest, RegisterRequest };
\ No newline at end of file
+export type { User, LoginRequest, LoginResult, RegisterRequest };
\ No newline at end of file